@@ -14,6 +14,34 @@ type Config struct {
 	RoleNotFoundID  string `json:"roleNotFoundId"`
 	CredentialsPath string `json:"credentialsPath"`
 	SpreadsheetID   string `json:"spreadsheetId"`
+	AdminChannelID  string `json:"adminChannelId"`
+
+	// Address for the bot's HTTP server (partner redirects and notification
+	// webhooks). Defaults to redirectListenAddr.
+	HTTPListenAddr string `json:"httpListenAddr"`
+
+	// Optional click-tracking redirect service for partner links
+	RedirectListenAddr   string `json:"redirectListenAddr"`
+	RedirectBaseURL      string `json:"redirectBaseUrl"`
+	RedirectSecret       string `json:"redirectSecret"`
+	RedirectLinkTTLHours int    `json:"redirectLinkTtlHours"`
+
+	// Days before a partner's end date to remind admins
+	PartnerExpiryReminderDays int `json:"partnerExpiryReminderDays"`
+	// Days before a partner's contract renewal date to remind admins
+	RenewalReminderDays int `json:"renewalReminderDays"`
+
+	// Channel for new partner announcements and the role they ping by default
+	PartnerAnnouncementsChannelID string `json:"partnerAnnouncementsChannelId"`
+	PartnerAnnouncementRoleID     string `json:"partnerAnnouncementRoleId"`
+
+	// Who may propose partners, and where proposals are reviewed
+	ProposePartnerRoleID   string `json:"proposePartnerRoleId"`
+	PartnerReviewChannelID string `json:"partnerReviewChannelId"`
+
+	// Partner spotlight channel and cron schedule (UTC)
+	SpotlightChannelID string `json:"spotlightChannelId"`
+	SpotlightSchedule  string `json:"spotlightSchedule"`
 }
 
 // Global config variable
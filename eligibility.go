@@ -0,0 +1,166 @@
+package main
+
+import (
+	"fmt"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/bwmarrin/discordgo"
+)
+
+// --- Partner Eligibility Section ---
+
+// Role-based eligibility rules for a single partner offering
+type PartnerEligibility struct {
+	AnyOf              []string `json:"any_of,omitempty"`
+	AllOf              []string `json:"all_of,omitempty"`
+	NoneOf             []string `json:"none_of,omitempty"`
+	NotEligibleMessage string   `json:"not_eligible_message,omitempty"`
+}
+
+const defaultNotEligibleMessage = "Join BW4E to access all of our partner offerings."
+
+// Check a member's roles against the partner's rules. Partners without any_of
+// or all_of rules still require the global access role, so none_of only
+// narrows the default and a custom message alone changes no access.
+func (p *Partner) isEligible(roles []string) bool {
+	e := p.Eligibility
+	if e == nil {
+		return hasRole(roles, accessRoleID)
+	}
+	for _, r := range e.NoneOf {
+		if hasRole(roles, r) {
+			return false
+		}
+	}
+	if len(e.AnyOf) == 0 && len(e.AllOf) == 0 {
+		return hasRole(roles, accessRoleID)
+	}
+	for _, r := range e.AllOf {
+		if !hasRole(roles, r) {
+			return false
+		}
+	}
+	if len(e.AnyOf) > 0 {
+		for _, r := range e.AnyOf {
+			if hasRole(roles, r) {
+				return true
+			}
+		}
+		return false
+	}
+	return true
+}
+
+// Message shown in place of the offering link when a member isn't eligible
+func (p *Partner) notEligibleMessage() string {
+	if p.Eligibility != nil && p.Eligibility.NotEligibleMessage != "" {
+		return p.Eligibility.NotEligibleMessage
+	}
+	return defaultNotEligibleMessage
+}
+
+// Human-readable summary of the eligibility rules for admin replies
+func describeEligibility(e *PartnerEligibility) string {
+	if e == nil {
+		return fmt.Sprintf("Default: members with <@&%s>.", accessRoleID)
+	}
+	mentions := func(ids []string) string {
+		if len(ids) == 0 {
+			return "-"
+		}
+		var out []string
+		for _, id := range ids {
+			out = append(out, "<@&"+id+">")
+		}
+		return strings.Join(out, " ")
+	}
+	anyOf := mentions(e.AnyOf)
+	if len(e.AnyOf) == 0 && len(e.AllOf) == 0 {
+		anyOf = fmt.Sprintf("<@&%s> (default)", accessRoleID)
+	}
+	return fmt.Sprintf("**Any of:** %s\n**All of:** %s\n**None of:** %s\n**Not eligible message:** %s",
+		anyOf, mentions(e.AllOf), mentions(e.NoneOf), (&Partner{Eligibility: e}).notEligibleMessage())
+}
+
+// Handle /partnereligibility: set, replace or clear a partner's eligibility rules
+func handlePartnerEligibilityCommand(s *discordgo.Session, i *discordgo.InteractionCreate) {
+	if !hasRole(i.Member.Roles, addPartnerRoleID) {
+		respondEphemeral(s, i, "You do not have permission to use this command.")
+		return
+	}
+	opts := optionMap(i.ApplicationCommandData().Options)
+	partnerName := strings.TrimSpace(opts["name"].StringValue())
+	partnersMu.RLock()
+	var original Partner
+	p := findPartner(partnerName)
+	if p != nil {
+		original = *p
+	}
+	partnersMu.RUnlock()
+	if p == nil {
+		respondEphemeral(s, i, "Partner not found.")
+		return
+	}
+
+	var e *PartnerEligibility
+	if opt, ok := opts["clear"]; !ok || !opt.BoolValue() {
+		e = &PartnerEligibility{}
+		if opt, ok := opts["any_of"]; ok {
+			e.AnyOf = parseIDList(opt.StringValue())
+		}
+		if opt, ok := opts["all_of"]; ok {
+			e.AllOf = parseIDList(opt.StringValue())
+		}
+		if opt, ok := opts["none_of"]; ok {
+			e.NoneOf = parseIDList(opt.StringValue())
+		}
+		if opt, ok := opts["message"]; ok {
+			e.NotEligibleMessage = strings.TrimSpace(opt.StringValue())
+		}
+		if len(e.AnyOf) == 0 && len(e.AllOf) == 0 && len(e.NoneOf) == 0 && e.NotEligibleMessage == "" {
+			respondEphemeral(s, i, "Provide at least one of any_of, all_of, none_of or message, or use clear to restore the default.")
+			return
+		}
+		if n := utf8.RuneCountInString(e.NotEligibleMessage); n > maxEmbedFieldValueLength {
+			respondEphemeral(s, i, formatPartnerErrors([]partnerFieldError{
+				{"message", fmt.Sprintf("is %d characters; the limit is %d", n, maxEmbedFieldValueLength)},
+			}))
+			return
+		}
+	}
+
+	// Checking the roles takes two Discord calls, so defer before making them
+	s.InteractionRespond(i.Interaction, &discordgo.InteractionResponse{
+		Type: discordgo.InteractionResponseDeferredChannelMessageWithSource,
+		Data: &discordgo.InteractionResponseData{Flags: discordgo.MessageFlagsEphemeral},
+	})
+	reply := func(content string) {
+		s.InteractionResponseEdit(i.Interaction, &discordgo.WebhookEdit{Content: &content})
+	}
+	if e != nil {
+		if problems := preflightRoles(s, eligibilityRoleChecks(original.Name, e)); len(problems) > 0 {
+			reply("Eligibility wasn't changed:\n- " + strings.Join(problems, "\n- "))
+			return
+		}
+	}
+
+	partnersMu.Lock()
+	p = findPartner(original.Name)
+	if p == nil || !partnersEqual(*p, original) {
+		partnersMu.Unlock()
+		reply(original.Name + " was changed by someone else in the meantime; run /partnereligibility again.")
+		return
+	}
+	p.Eligibility = e
+	if err := savePartners(); err != nil {
+		p.Eligibility = original.Eligibility
+		partnersMu.Unlock()
+		reply("Failed to save partners: " + err.Error())
+		return
+	}
+	updated := *p
+	partnersMu.Unlock()
+	recordPartnerRevision(partnerActionUpdate, i.Member.User.ID, updated)
+	reply(fmt.Sprintf("Eligibility for %s updated.\n%s", updated.Name, describeEligibility(updated.Eligibility)))
+}
@@ -86,6 +86,7 @@ func onMessageCreate(s *discordgo.Session, m *discordgo.MessageCreate) {
 	log.Printf("Assigning role %s to user %s\n", roleID, m.Author.Username)
 	if addErr := s.GuildMemberRoleAdd(config.GuildID, member.User.ID, roleID); addErr != nil {
 		log.Printf("Error assigning role: %v", addErr)
+		go explainRoleFailure(s, "email verification", roleID)
 		return
 	}
 
@@ -11,9 +11,17 @@ import (
 // Handle interactions for slash commands and partner/notification buttons
 func onInteractionCreate(s *discordgo.Session, i *discordgo.InteractionCreate) {
 	// --- Partner commands ---
+	if i.Type == discordgo.InteractionApplicationCommandAutocomplete && i.ApplicationCommandData().Name == "partner" {
+		handlePartnerAutocomplete(s, i)
+		return
+	}
+	if i.Type == discordgo.InteractionApplicationCommand && i.ApplicationCommandData().Name == "partner" {
+		handlePartnerLookupCommand(s, i)
+		return
+	}
 	if i.Type == discordgo.InteractionApplicationCommand && i.ApplicationCommandData().Name == "addpartner" {
-		opts := i.ApplicationCommandData().Options
-		newName := strings.TrimSpace(opts[0].StringValue())
+		opts := optionMap(i.ApplicationCommandData().Options)
+		newName := strings.TrimSpace(opts["name"].StringValue())
 		hasRole := false
 		for _, r := range i.Member.Roles {
 			if r == addPartnerRoleID {
@@ -31,48 +39,94 @@ func onInteractionCreate(s *discordgo.Session, i *discordgo.InteractionCreate) {
 			})
 			return
 		}
-		for _, p := range partners {
-			if strings.EqualFold(strings.TrimSpace(p.Name), newName) {
-				s.InteractionRespond(i.Interaction, &discordgo.InteractionResponse{
-					Type: discordgo.InteractionResponseChannelMessageWithSource,
-					Data: &discordgo.InteractionResponseData{
-						Content: "A partner with that name already exists. Please choose a unique name.",
-						Flags:   discordgo.MessageFlagsEphemeral,
-					},
-				})
-				return
-			}
+		partnersMu.RLock()
+		exists := findPartner(newName) != nil
+		partnersMu.RUnlock()
+		if exists {
+			s.InteractionRespond(i.Interaction, &discordgo.InteractionResponse{
+				Type: discordgo.InteractionResponseChannelMessageWithSource,
+				Data: &discordgo.InteractionResponseData{
+					Content: "A partner with that name already exists. Please choose a unique name.",
+					Flags:   discordgo.MessageFlagsEphemeral,
+				},
+			})
+			return
 		}
 		p := Partner{
 			Name:        newName,
-			Description: opts[1].StringValue(),
-			Offering:    opts[2].StringValue(),
-			LogoURL:     opts[3].StringValue(),
-			Link:        opts[4].StringValue(),
-			Emoji:       opts[5].StringValue(),
+			Description: opts["description"].StringValue(),
+			Offering:    opts["offering"].StringValue(),
+			Link:        opts["link"].StringValue(),
 		}
-		partners = append(partners, p)
-		if err := savePartners(); err != nil {
-			log.Printf("Error saving partners: %v", err)
+		if opt, ok := opts["logo"]; ok {
+			p.LogoURL = opt.StringValue()
+		}
+		if opt, ok := opts["emoji"]; ok {
+			p.Emoji = opt.StringValue()
+		}
+		if opt, ok := opts["tags"]; ok {
+			p.Tags = parseTags(opt.StringValue())
 		}
+		if err := applyPartnerScheduleOptions(&p, opts); err != nil {
+			respondEphemeral(s, i, err.Error())
+			return
+		}
+		if err := applyPartnerMediaOptions(&p, opts); err != nil {
+			respondEphemeral(s, i, err.Error())
+			return
+		}
+		logo, err := parsePartnerLogoOptions(i, &p, opts)
+		if err != nil {
+			respondEphemeral(s, i, err.Error())
+			return
+		}
+		if errs := logo.filterErrors(validatePartner(s, &p)); len(errs) > 0 {
+			respondEphemeral(s, i, formatPartnerErrors(errs))
+			return
+		}
+
+		// Downloading the logo and uploading its emoji can outlast the interaction deadline
 		s.InteractionRespond(i.Interaction, &discordgo.InteractionResponse{
-			Type: discordgo.InteractionResponseChannelMessageWithSource,
-			Data: &discordgo.InteractionResponseData{
-				Content: "Partner added!",
-				Flags:   discordgo.MessageFlagsEphemeral,
-			},
+			Type: discordgo.InteractionResponseDeferredChannelMessageWithSource,
+			Data: &discordgo.InteractionResponseData{Flags: discordgo.MessageFlagsEphemeral},
 		})
-		botUser, _ := s.User("@me")
-		messages, err := s.ChannelMessages(partnersChannelID, 50, "", "", "")
-		if err == nil {
-			for _, msg := range messages {
-				if msg.Author != nil && msg.Author.ID == botUser.ID && msg.Content == "No partners configured yet." {
-					_ = s.ChannelMessageDelete(partnersChannelID, msg.ID)
-					break
-				}
-			}
+		reply := func(content string) {
+			s.InteractionResponseEdit(i.Interaction, &discordgo.WebhookEdit{Content: &content})
+		}
+		if err := logo.prepare(s, &p); err != nil {
+			reply(err.Error())
+			return
+		}
+
+		partnersMu.Lock()
+		if findPartner(newName) != nil {
+			partnersMu.Unlock()
+			logo.discard(s)
+			reply("A partner with that name already exists. Please choose a unique name.")
+			return
+		}
+		previous := append([]Partner(nil), partners...)
+		purgeDeletedPartner(newName)
+		partners = append(partners, p)
+		if err := savePartners(); err != nil {
+			partners = previous
+			partnersMu.Unlock()
+			logo.discard(s)
+			reply("Failed to save partners: " + err.Error())
+			return
+		}
+		content := "Partner added!" + describePartnerSchedule(&p)
+		if err := logo.commit(); err != nil {
+			log.Printf("Error storing partner logo for %s: %v", p.Name, err)
+			content += " The uploaded logo couldn't be stored: " + err.Error()
+		}
+		partnersMu.Unlock()
+		recordPartnerRevision(partnerActionCreate, i.Member.User.ID, p)
+		if opt, ok := opts["announce"]; ok && opt.BoolValue() {
+			content += announceOrQueuePartner(s, p.Name, announcementPingRole(opts))
 		}
-		updatePartnersEmbed(s, botUser.ID)
+		reply(content)
+		refreshPartnersPanel(s)
 		return
 	}
 	if i.Type == discordgo.InteractionApplicationCommand && i.ApplicationCommandData().Name == "delpartner" {
@@ -93,16 +147,11 @@ func onInteractionCreate(s *discordgo.Session, i *discordgo.InteractionCreate) {
 			})
 			return
 		}
+		partnersMu.Lock()
 		partnerName := strings.TrimSpace(i.ApplicationCommandData().Options[0].StringValue())
-		found := false
-		for idx, p := range partners {
-			if strings.EqualFold(strings.TrimSpace(p.Name), partnerName) {
-				partners = append(partners[:idx], partners[idx+1:]...)
-				found = true
-				break
-			}
-		}
-		if !found {
+		p := findPartner(partnerName)
+		if p == nil {
+			partnersMu.Unlock()
 			s.InteractionRespond(i.Interaction, &discordgo.InteractionResponse{
 				Type: discordgo.InteractionResponseChannelMessageWithSource,
 				Data: &discordgo.InteractionResponseData{
@@ -112,9 +161,14 @@ func onInteractionCreate(s *discordgo.Session, i *discordgo.InteractionCreate) {
 			})
 			return
 		}
-		if err := savePartners(); err != nil {
+		if err := softDeletePartner(p, i.Member.User.ID); err != nil {
+			partnersMu.Unlock()
 			log.Printf("Error saving partners: %v", err)
+			respondEphemeral(s, i, "Failed to delete the partner: "+err.Error())
+			return
 		}
+		deletedName := p.Name
+		partnersMu.Unlock()
 		botUser, _ := s.User("@me")
 		messages, err := s.ChannelMessages(partnersChannelID, 50, "", "", "")
 		if err == nil {
@@ -131,95 +185,182 @@ func onInteractionCreate(s *discordgo.Session, i *discordgo.InteractionCreate) {
 			Data: &discordgo.InteractionResponseData{
 				Content: "Partner deleted and embed refreshed.",
 				Flags:   discordgo.MessageFlagsEphemeral,
+				Components: []discordgo.MessageComponent{
+					discordgo.ActionsRow{Components: []discordgo.MessageComponent{
+						discordgo.Button{
+							Label:    "Undo",
+							CustomID: partnerUndoCustomPrefix + deletedName,
+							Style:    discordgo.SecondaryButton,
+							Emoji:    &discordgo.ComponentEmoji{Name: "↩️"},
+						},
+					}},
+				},
 			},
 		})
 		return
 	}
 
+	if i.Type == discordgo.InteractionApplicationCommand && i.ApplicationCommandData().Name == "proposepartner" {
+		handleProposePartnerCommand(s, i)
+		return
+	}
+	if i.Type == discordgo.InteractionMessageComponent && strings.HasPrefix(i.MessageComponentData().CustomID, "proposal_") {
+		handleProposalButton(s, i)
+		return
+	}
+	if i.Type == discordgo.InteractionModalSubmit && strings.HasPrefix(i.ModalSubmitData().CustomID, "proposalnote_") {
+		handleProposalNoteModal(s, i)
+		return
+	}
+	if i.Type == discordgo.InteractionApplicationCommand && i.ApplicationCommandData().Name == "spotlight" {
+		handleSpotlightCommand(s, i)
+		return
+	}
+	if i.Type == discordgo.InteractionApplicationCommand && i.ApplicationCommandData().Name == "partnerinfo" {
+		handlePartnerInfoCommand(s, i)
+		return
+	}
+	if i.Type == discordgo.InteractionApplicationCommand && i.ApplicationCommandData().Name == "setpartnerinfo" {
+		handleSetPartnerInfoCommand(s, i)
+		return
+	}
+	if i.Type == discordgo.InteractionApplicationCommand && i.ApplicationCommandData().Name == "editpartner" {
+		handleEditPartnerCommand(s, i)
+		return
+	}
+	if i.Type == discordgo.InteractionApplicationCommand && i.ApplicationCommandData().Name == "partnerhistory" {
+		handlePartnerHistoryCommand(s, i)
+		return
+	}
+	if i.Type == discordgo.InteractionMessageComponent && strings.HasPrefix(i.MessageComponentData().CustomID, partnerUndoCustomPrefix) {
+		handlePartnerUndoButton(s, i)
+		return
+	}
+	if i.Type == discordgo.InteractionApplicationCommand && i.ApplicationCommandData().Name == "announcepartner" {
+		handleAnnouncePartnerCommand(s, i)
+		return
+	}
+	if i.Type == discordgo.InteractionApplicationCommand && i.ApplicationCommandData().Name == "partnerschedule" {
+		handlePartnerScheduleCommand(s, i)
+		return
+	}
+	if i.Type == discordgo.InteractionApplicationCommand && i.ApplicationCommandData().Name == "partnereligibility" {
+		handlePartnerEligibilityCommand(s, i)
+		return
+	}
+
+	if i.Type == discordgo.InteractionApplicationCommand && i.ApplicationCommandData().Name == "uploadpartnercodes" {
+		handleUploadPartnerCodesCommand(s, i)
+		return
+	}
+	if i.Type == discordgo.InteractionApplicationCommand && i.ApplicationCommandData().Name == "partnercodes" {
+		handlePartnerCodesCommand(s, i)
+		return
+	}
+	if i.Type == discordgo.InteractionApplicationCommand && i.ApplicationCommandData().Name == "exportpartners" {
+		handleExportPartnersCommand(s, i)
+		return
+	}
+	if i.Type == discordgo.InteractionApplicationCommand && i.ApplicationCommandData().Name == "importpartners" {
+		handleImportPartnersCommand(s, i)
+		return
+	}
+	if i.Type == discordgo.InteractionMessageComponent && strings.HasPrefix(i.MessageComponentData().CustomID, "partnerimport_") {
+		handlePartnerImportButton(s, i)
+		return
+	}
+	if i.Type == discordgo.InteractionApplicationCommand && i.ApplicationCommandData().Name == "partnerstats" {
+		handlePartnerStatsCommand(s, i)
+		return
+	}
+
 	// --- Notification channel commands ---
 	if i.Type == discordgo.InteractionApplicationCommand && i.ApplicationCommandData().Name == "addnotificationchannel" {
-		opts := i.ApplicationCommandData().Options
-		name := opts[0].StringValue()
-		channelID := parseID(opts[1].StringValue())
-		roleID := parseID(opts[2].StringValue())
-		notificationRoleID := parseID(opts[3].StringValue())
-		for _, nc := range notificationChannels {
-			if strings.EqualFold(nc.Name, name) {
-				s.InteractionRespond(i.Interaction, &discordgo.InteractionResponse{
-					Type: discordgo.InteractionResponseChannelMessageWithSource,
-					Data: &discordgo.InteractionResponseData{
-						Content: "A notification channel with that name already exists.",
-						Flags:   discordgo.MessageFlagsEphemeral,
-					},
-				})
-				return
-			}
-		}
-		notificationChannels = append(notificationChannels, NotificationChannel{
-			Name: name, ChannelID: channelID, AccessRoleID: roleID, NotificationRoleID: notificationRoleID,
-		})
-		saveNotificationChannels()
-		sendNotificationsEmbed(s)
-		s.InteractionRespond(i.Interaction, &discordgo.InteractionResponse{
-			Type: discordgo.InteractionResponseChannelMessageWithSource,
-			Data: &discordgo.InteractionResponseData{
-				Content: "Notification channel added.",
-				Flags:   discordgo.MessageFlagsEphemeral,
-			},
-		})
+		handleAddNotificationChannelCommand(s, i)
+		return
+	}
+	if i.Type == discordgo.InteractionApplicationCommand && i.ApplicationCommandData().Name == "editnotificationchannel" {
+		handleEditNotificationChannelCommand(s, i)
 		return
 	}
 	if i.Type == discordgo.InteractionApplicationCommand && i.ApplicationCommandData().Name == "delnotificationchannel" {
-		name := i.ApplicationCommandData().Options[0].StringValue()
-		found := false
-		for idx, nc := range notificationChannels {
-			if strings.EqualFold(nc.Name, name) {
-				notificationChannels = append(notificationChannels[:idx], notificationChannels[idx+1:]...)
-				found = true
-				break
-			}
-		}
-		if !found {
-			s.InteractionRespond(i.Interaction, &discordgo.InteractionResponse{
-				Type: discordgo.InteractionResponseChannelMessageWithSource,
-				Data: &discordgo.InteractionResponseData{
-					Content: "Notification channel not found.",
-					Flags:   discordgo.MessageFlagsEphemeral,
-				},
-			})
-			return
-		}
-		saveNotificationChannels()
-		sendNotificationsEmbed(s)
-		s.InteractionRespond(i.Interaction, &discordgo.InteractionResponse{
-			Type: discordgo.InteractionResponseChannelMessageWithSource,
-			Data: &discordgo.InteractionResponseData{
-				Content: "Notification channel deleted.",
-				Flags:   discordgo.MessageFlagsEphemeral,
-			},
-		})
+		handleDelNotificationChannelCommand(s, i)
+		return
+	}
+
+	if i.Type == discordgo.InteractionApplicationCommandAutocomplete && (i.ApplicationCommandData().Name == "announce" ||
+		i.ApplicationCommandData().Name == "addfeed" || i.ApplicationCommandData().Name == "delfeed" ||
+		i.ApplicationCommandData().Name == "webhooksecret" || i.ApplicationCommandData().Name == "editnotificationchannel") {
+		handleNotificationChannelAutocomplete(s, i)
+		return
+	}
+	if i.Type == discordgo.InteractionApplicationCommand && i.ApplicationCommandData().Name == "announce" {
+		handleAnnounceCommand(s, i)
+		return
+	}
+	if i.Type == discordgo.InteractionModalSubmit && strings.HasPrefix(i.ModalSubmitData().CustomID, "announcemodal_") {
+		handleAnnounceModal(s, i)
+		return
+	}
+	if i.Type == discordgo.InteractionMessageComponent && strings.HasPrefix(i.MessageComponentData().CustomID, "announcement_") {
+		handleAnnouncementButton(s, i)
+		return
+	}
+	if i.Type == discordgo.InteractionApplicationCommand && i.ApplicationCommandData().Name == "addfeed" {
+		handleAddFeedCommand(s, i)
+		return
+	}
+	if i.Type == discordgo.InteractionApplicationCommand && i.ApplicationCommandData().Name == "delfeed" {
+		handleDelFeedCommand(s, i)
+		return
+	}
+	if i.Type == discordgo.InteractionApplicationCommand && i.ApplicationCommandData().Name == "webhooksecret" {
+		handleWebhookSecretCommand(s, i)
+		return
+	}
+	if i.Type == discordgo.InteractionApplicationCommand && i.ApplicationCommandData().Name == "unsubscribeall" {
+		handleUnsubscribeAllCommand(s, i)
 		return
 	}
 
 	// --- Notification button logic ---
+	if i.Type == discordgo.InteractionMessageComponent && i.MessageComponentData().CustomID == notificationPrefsOpenID {
+		handleNotificationPrefsOpen(s, i)
+		return
+	}
+	if i.Type == discordgo.InteractionMessageComponent && (i.MessageComponentData().CustomID == notificationPrefsWithID ||
+		i.MessageComponentData().CustomID == notificationPrefsWithoutID) {
+		handleNotificationPrefsSelect(s, i)
+		return
+	}
+	if i.Type == discordgo.InteractionMessageComponent && i.MessageComponentData().CustomID == notificationPrefsSaveID {
+		handleNotificationPrefsSave(s, i)
+		return
+	}
+	if i.Type == discordgo.InteractionMessageComponent && strings.HasPrefix(i.MessageComponentData().CustomID, "digest_") {
+		handleDigestButton(s, i)
+		return
+	}
+	if i.Type == discordgo.InteractionMessageComponent && strings.HasPrefix(i.MessageComponentData().CustomID, "notification_unsub_") {
+		handleNotificationUnsubscribeButton(s, i)
+		return
+	}
 	if i.Type == discordgo.InteractionMessageComponent && strings.HasPrefix(i.MessageComponentData().CustomID, "notification_") &&
 		!strings.HasPrefix(i.MessageComponentData().CustomID, "notification_sub_") {
 
 		name := strings.TrimPrefix(i.MessageComponentData().CustomID, "notification_")
-		var nc *NotificationChannel
-		for idx, n := range notificationChannels {
-			if n.Name == name {
-				nc = &notificationChannels[idx]
-				break
-			}
-		}
-		if nc == nil {
+		found, ok := findNotificationChannel(name)
+		if !ok {
 			return
 		}
+		nc := &found
 
+		state := subscriptionState(i.Member.Roles, nc)
+		cadence := digestCadence(i.Member.User.ID, nc.Name)
 		embed := &discordgo.MessageEmbed{
-			Title:       "Notification Subscription",
-			Description: fmt.Sprintf("How would you like to subscribe to %s?", nc.Name),
+			Title: "Notification Subscription",
+			Description: fmt.Sprintf("%s\n%s\n\nHow would you like to subscribe to %s?",
+				describeSubscriptionState(state, nc), describeDigestState(cadence), nc.Name),
 		}
 
 		components := []discordgo.MessageComponent{
@@ -230,15 +371,25 @@ func onInteractionCreate(s *discordgo.Session, i *discordgo.InteractionCreate) {
 						CustomID: "notification_sub_with_" + nc.Name,
 						Style:    discordgo.SuccessButton,
 						Emoji:    &discordgo.ComponentEmoji{Name: "🔔"},
+						Disabled: state == subscribedWithNotifications,
 					},
 					discordgo.Button{
 						Label:    "Subscribe without Notifications",
 						CustomID: "notification_sub_without_" + nc.Name,
 						Style:    discordgo.SecondaryButton,
 						Emoji:    &discordgo.ComponentEmoji{Name: "🔕"},
+						Disabled: state == subscribedWithoutNotifications,
+					},
+					discordgo.Button{
+						Label:    "Unsubscribe",
+						CustomID: "notification_unsub_" + nc.Name,
+						Style:    discordgo.DangerButton,
+						Emoji:    &discordgo.ComponentEmoji{Name: "🚪"},
+						Disabled: state == notSubscribed,
 					},
 				},
 			},
+			digestButtonsRow(cadence, nc.Name),
 		}
 
 		s.InteractionRespond(i.Interaction, &discordgo.InteractionResponse{
@@ -266,14 +417,8 @@ func onInteractionCreate(s *discordgo.Session, i *discordgo.InteractionCreate) {
 			return
 		}
 
-		var nc *NotificationChannel
-		for idx, n := range notificationChannels {
-			if n.Name == name {
-				nc = &notificationChannels[idx]
-				break
-			}
-		}
-		if nc == nil {
+		nc, ok := findNotificationChannel(name)
+		if !ok {
 			s.InteractionRespond(i.Interaction, &discordgo.InteractionResponse{
 				Type: discordgo.InteractionResponseChannelMessageWithSource,
 				Data: &discordgo.InteractionResponseData{
@@ -305,6 +450,7 @@ func onInteractionCreate(s *discordgo.Session, i *discordgo.InteractionCreate) {
 		}
 		if err1 != nil || err2 != nil {
 			log.Printf("Role assignment error: %v %v", err1, err2)
+			go explainRoleFailure(s, nc.Name+" subscription", nc.AccessRoleID, nc.NotificationRoleID)
 			s.InteractionRespond(i.Interaction, &discordgo.InteractionResponse{
 				Type: discordgo.InteractionResponseChannelMessageWithSource,
 				Data: &discordgo.InteractionResponseData{
@@ -335,46 +481,25 @@ func onInteractionCreate(s *discordgo.Session, i *discordgo.InteractionCreate) {
 	}
 
 	// --- Partner button logic ---
+	if i.Type == discordgo.InteractionMessageComponent && strings.HasPrefix(i.MessageComponentData().CustomID, "partner_redeem_") {
+		handlePartnerRedeemButton(s, i)
+		return
+	}
 	if i.Type == discordgo.InteractionMessageComponent && strings.HasPrefix(i.MessageComponentData().CustomID, "partner_") {
 		partnerName := strings.TrimPrefix(i.MessageComponentData().CustomID, "partner_")
+		partnersMu.RLock()
 		var p *Partner
-		for idx, partner := range partners {
+		for _, partner := range partners {
 			if partner.Name == partnerName {
-				p = &partners[idx]
+				p = &partner
 				break
 			}
 		}
+		partnersMu.RUnlock()
 		if p == nil {
 			return
 		}
-		hasRole := false
-		for _, r := range i.Member.Roles {
-			if r == accessRoleID {
-				hasRole = true
-				break
-			}
-		}
-		embed := &discordgo.MessageEmbed{
-			Title:       p.Name,
-			Description: fmt.Sprintf("%s\n\n**Offering:** %s", p.Description, p.Offering),
-			Thumbnail:   &discordgo.MessageEmbedThumbnail{URL: p.LogoURL},
-		}
-		if hasRole {
-			embed.Fields = []*discordgo.MessageEmbedField{
-				{Name: "Access Offering", Value: fmt.Sprintf("[Click here](%s)", p.Link), Inline: false},
-			}
-		} else {
-			embed.Fields = []*discordgo.MessageEmbedField{
-				{Name: "Access Offering", Value: "Join BW4E to access all of our partner offerings.", Inline: false},
-			}
-		}
-		s.InteractionRespond(i.Interaction, &discordgo.InteractionResponse{
-			Type: discordgo.InteractionResponseChannelMessageWithSource,
-			Data: &discordgo.InteractionResponseData{
-				Embeds: []*discordgo.MessageEmbed{embed},
-				Flags:  discordgo.MessageFlagsEphemeral,
-			},
-		})
+		respondWithPartnerView(s, i, p)
 		return
 	}
 
@@ -387,6 +512,10 @@ func registerCommands(s *discordgo.Session) {
 		log.Fatalf("Cannot register commands: Discord session state is not initialized.")
 	}
 
+	// Only staff who can manage messages see /announce and the feed commands by default
+	staffPermissions := int64(discordgo.PermissionManageMessages)
+	adminPermissions := int64(discordgo.PermissionAdministrator)
+
 	commands := []*discordgo.ApplicationCommand{
 		{
 			Name:        "addpartner",
@@ -395,9 +524,108 @@ func registerCommands(s *discordgo.Session) {
 				{Type: discordgo.ApplicationCommandOptionString, Name: "name",        Description: "Partner Name", Required: true},
 				{Type: discordgo.ApplicationCommandOptionString, Name: "description", Description: "Description",  Required: true},
 				{Type: discordgo.ApplicationCommandOptionString, Name: "offering",    Description: "Offering",     Required: true},
-				{Type: discordgo.ApplicationCommandOptionString, Name: "logo",        Description: "Logo URL",     Required: true},
 				{Type: discordgo.ApplicationCommandOptionString, Name: "link",        Description: "Offering Link",Required: true},
-				{Type: discordgo.ApplicationCommandOptionString, Name: "emoji",       Description: "Emoji",        Required: true},
+				{Type: discordgo.ApplicationCommandOptionString, Name: "logo",        Description: "Logo URL (or attach logo_file)"},
+				{Type: discordgo.ApplicationCommandOptionAttachment, Name: "logo_file", Description: "Logo image to upload (PNG, JPEG or GIF)"},
+				{Type: discordgo.ApplicationCommandOptionString, Name: "emoji",       Description: "Emoji (or use upload_emoji)"},
+				{Type: discordgo.ApplicationCommandOptionBoolean, Name: "upload_emoji", Description: "Also upload logo_file as a server emoji for the button"},
+				{Type: discordgo.ApplicationCommandOptionString, Name: "starts_at",   Description: "Show from (YYYY-MM-DD or YYYY-MM-DD HH:MM, UTC)"},
+				{Type: discordgo.ApplicationCommandOptionString, Name: "ends_at",     Description: "Hide from (YYYY-MM-DD or YYYY-MM-DD HH:MM, UTC)"},
+				{Type: discordgo.ApplicationCommandOptionString,  Name: "tags",      Description: "Search tags, comma separated (e.g. design, video)"},
+				{Type: discordgo.ApplicationCommandOptionString,  Name: "links",     Description: "Extra link buttons: Label|URL|emoji; Label|URL (up to 4)"},
+				{Type: discordgo.ApplicationCommandOptionString,  Name: "banner",    Description: "Banner image URL"},
+				{Type: discordgo.ApplicationCommandOptionString,  Name: "color",     Description: "Brand colour, e.g. #5865F2"},
+				{Type: discordgo.ApplicationCommandOptionString,  Name: "footer",    Description: "Footer text for the offer card"},
+				{Type: discordgo.ApplicationCommandOptionBoolean, Name: "announce",  Description: "Post an announcement when the partner goes live"},
+				{Type: discordgo.ApplicationCommandOptionBoolean, Name: "ping",      Description: "Ping a role with the announcement (default: quiet)"},
+				{Type: discordgo.ApplicationCommandOptionRole,    Name: "ping_role", Description: "Role to ping instead of the default announcement role"},
+			},
+		},
+		{
+			Name:        "announcepartner",
+			Description: "Post an announcement for an existing partner.",
+			Options: []*discordgo.ApplicationCommandOption{
+				{Type: discordgo.ApplicationCommandOptionString, Name: "name", Description: "Partner Name", Required: true},
+				{Type: discordgo.ApplicationCommandOptionBoolean, Name: "ping", Description: "Ping a role with the announcement (default: quiet)"},
+				{Type: discordgo.ApplicationCommandOptionRole, Name: "ping_role", Description: "Role to ping instead of the default announcement role"},
+			},
+		},
+		{
+			Name:        "proposepartner",
+			Description: "Propose a new partner for staff approval.",
+			Options: []*discordgo.ApplicationCommandOption{
+				{Type: discordgo.ApplicationCommandOptionString, Name: "name", Description: "Partner Name", Required: true},
+				{Type: discordgo.ApplicationCommandOptionString, Name: "description", Description: "Description", Required: true},
+				{Type: discordgo.ApplicationCommandOptionString, Name: "offering", Description: "Offering", Required: true},
+				{Type: discordgo.ApplicationCommandOptionString, Name: "logo", Description: "Logo URL", Required: true},
+				{Type: discordgo.ApplicationCommandOptionString, Name: "link", Description: "Offering Link", Required: true},
+				{Type: discordgo.ApplicationCommandOptionString, Name: "emoji", Description: "Emoji", Required: true},
+				{Type: discordgo.ApplicationCommandOptionString, Name: "tags", Description: "Search tags, comma separated (e.g. design, video)"},
+			},
+		},
+		{
+			Name:        "editpartner",
+			Description: "Edit an existing partner.",
+			Options: []*discordgo.ApplicationCommandOption{
+				{Type: discordgo.ApplicationCommandOptionString, Name: "name", Description: "Partner Name", Required: true},
+				{Type: discordgo.ApplicationCommandOptionString, Name: "description", Description: "Description"},
+				{Type: discordgo.ApplicationCommandOptionString, Name: "offering", Description: "Offering"},
+				{Type: discordgo.ApplicationCommandOptionString, Name: "logo", Description: "Logo URL"},
+				{Type: discordgo.ApplicationCommandOptionAttachment, Name: "logo_file", Description: "Logo image to upload (PNG, JPEG or GIF)"},
+				{Type: discordgo.ApplicationCommandOptionBoolean, Name: "upload_emoji", Description: "Also upload logo_file as a server emoji for the button"},
+				{Type: discordgo.ApplicationCommandOptionString, Name: "link", Description: "Offering Link"},
+				{Type: discordgo.ApplicationCommandOptionString, Name: "emoji", Description: "Emoji"},
+				{Type: discordgo.ApplicationCommandOptionString, Name: "tags", Description: "Search tags, comma separated (e.g. design, video)"},
+				{Type: discordgo.ApplicationCommandOptionString, Name: "links", Description: "Extra link buttons: Label|URL|emoji; Label|URL (up to 4), or none"},
+				{Type: discordgo.ApplicationCommandOptionString, Name: "banner", Description: "Banner image URL, or none"},
+				{Type: discordgo.ApplicationCommandOptionString, Name: "color", Description: "Brand colour, e.g. #5865F2, or none"},
+				{Type: discordgo.ApplicationCommandOptionString, Name: "footer", Description: "Footer text for the offer card, or none"},
+			},
+		},
+		{
+			Name:        "spotlight",
+			Description: "Show the partner spotlight rotation, pin next week's partner, or post now.",
+			Options: []*discordgo.ApplicationCommandOption{
+				{Type: discordgo.ApplicationCommandOptionString, Name: "pin", Description: "Partner to feature in the next spotlight"},
+				{Type: discordgo.ApplicationCommandOptionBoolean, Name: "unpin", Description: "Go back to normal rotation"},
+				{Type: discordgo.ApplicationCommandOptionBoolean, Name: "post_now", Description: "Post the next spotlight immediately"},
+			},
+		},
+		{
+			Name:        "partnerinfo",
+			Description: "Show a partner's private admin details.",
+			Options: []*discordgo.ApplicationCommandOption{
+				{Type: discordgo.ApplicationCommandOptionString, Name: "name", Description: "Partner Name", Required: true},
+			},
+		},
+		{
+			Name:        "setpartnerinfo",
+			Description: "Update a partner's private admin details. Use none to clear a field.",
+			Options: []*discordgo.ApplicationCommandOption{
+				{Type: discordgo.ApplicationCommandOptionString, Name: "name", Description: "Partner Name", Required: true},
+				{Type: discordgo.ApplicationCommandOptionString, Name: "contact", Description: "Contact person"},
+				{Type: discordgo.ApplicationCommandOptionString, Name: "email", Description: "Contact email"},
+				{Type: discordgo.ApplicationCommandOptionString, Name: "contract", Description: "Contract reference"},
+				{Type: discordgo.ApplicationCommandOptionString, Name: "renewal", Description: "Renewal date (YYYY-MM-DD, UTC)"},
+				{Type: discordgo.ApplicationCommandOptionString, Name: "notes", Description: "Internal notes"},
+			},
+		},
+		{
+			Name:        "partnerhistory",
+			Description: "Show a partner's change history or restore an earlier version.",
+			Options: []*discordgo.ApplicationCommandOption{
+				{Type: discordgo.ApplicationCommandOptionString, Name: "name", Description: "Partner Name", Required: true},
+				{Type: discordgo.ApplicationCommandOptionInteger, Name: "restore", Description: "Revision number to restore"},
+			},
+		},
+		{
+			Name:        "partnerschedule",
+			Description: "Set or clear a partner's start and end dates.",
+			Options: []*discordgo.ApplicationCommandOption{
+				{Type: discordgo.ApplicationCommandOptionString, Name: "name", Description: "Partner Name", Required: true},
+				{Type: discordgo.ApplicationCommandOptionString, Name: "starts_at", Description: "Show from (YYYY-MM-DD or YYYY-MM-DD HH:MM, UTC)"},
+				{Type: discordgo.ApplicationCommandOptionString, Name: "ends_at", Description: "Hide from (YYYY-MM-DD or YYYY-MM-DD HH:MM, UTC)"},
+				{Type: discordgo.ApplicationCommandOptionBoolean, Name: "clear", Description: "Remove both dates so the partner is always shown"},
 			},
 		},
 		{
@@ -408,20 +636,148 @@ func registerCommands(s *discordgo.Session) {
 			},
 		},
 		{
-			Name:        "addnotificationchannel",
-			Description: "Add a notification channel.",
+			Name:        "partnereligibility",
+			Description: "Set which roles can access a partner's offering.",
+			Options: []*discordgo.ApplicationCommandOption{
+				{Type: discordgo.ApplicationCommandOptionString, Name: "name", Description: "Partner Name", Required: true},
+				{Type: discordgo.ApplicationCommandOptionString, Name: "any_of", Description: "Member needs at least one of these roles (@ or IDs)"},
+				{Type: discordgo.ApplicationCommandOptionString, Name: "all_of", Description: "Member needs every one of these roles (@ or IDs)"},
+				{Type: discordgo.ApplicationCommandOptionString, Name: "none_of", Description: "Member must have none of these roles (@ or IDs)"},
+				{Type: discordgo.ApplicationCommandOptionString, Name: "message", Description: "Message shown to members who aren't eligible"},
+				{Type: discordgo.ApplicationCommandOptionBoolean, Name: "clear", Description: "Remove custom rules and use the default access role"},
+			},
+		},
+		{
+			Name:        "uploadpartnercodes",
+			Description: "Upload single-use redemption codes for a partner.",
+			Options: []*discordgo.ApplicationCommandOption{
+				{Type: discordgo.ApplicationCommandOptionString, Name: "name", Description: "Partner Name", Required: true},
+				{Type: discordgo.ApplicationCommandOptionAttachment, Name: "file", Description: "Text file (one code per line) or CSV (codes in first column)", Required: true},
+				{Type: discordgo.ApplicationCommandOptionInteger, Name: "threshold", Description: "Alert admins when this many codes or fewer remain", MinValue: floatPtr(0)},
+				{Type: discordgo.ApplicationCommandOptionBoolean, Name: "replace", Description: "Discard unassigned codes before adding these"},
+			},
+		},
+		{
+			Name:        "partnercodes",
+			Description: "Show redemption code stock for each partner.",
+		},
+		{
+			Name:        "partnerstats",
+			Description: "Show partner views, link reveals and redemptions.",
+			Options: []*discordgo.ApplicationCommandOption{
+				{
+					Type: discordgo.ApplicationCommandOptionInteger, Name: "days", Description: "Reporting window (default 30 days)",
+					Choices: []*discordgo.ApplicationCommandOptionChoice{
+						{Name: "Last 7 days", Value: 7},
+						{Name: "Last 30 days", Value: 30},
+						{Name: "Last 90 days (quarter)", Value: 90},
+						{Name: "Last 365 days", Value: 365},
+					},
+				},
+				{Type: discordgo.ApplicationCommandOptionBoolean, Name: "export", Description: "Attach a daily CSV breakdown instead"},
+			},
+		},
+		{
+			Name:        "exportpartners",
+			Description: "Download the partner list as a file.",
+			Options: []*discordgo.ApplicationCommandOption{
+				{
+					Type: discordgo.ApplicationCommandOptionString, Name: "format", Description: "File format (default JSON)",
+					Choices: []*discordgo.ApplicationCommandOptionChoice{
+						{Name: "JSON", Value: "json"},
+						{Name: "CSV", Value: "csv"},
+					},
+				},
+			},
+		},
+		{
+			Name:        "importpartners",
+			Description: "Replace the partner list from a JSON or CSV file.",
+			Options: []*discordgo.ApplicationCommandOption{
+				{Type: discordgo.ApplicationCommandOptionAttachment, Name: "file", Description: "partners.json or partners.csv", Required: true},
+			},
+		},
+		{
+			Name:                     "addnotificationchannel",
+			Description:              "Add a notification channel.",
+			DefaultMemberPermissions: &adminPermissions,
 			Options: []*discordgo.ApplicationCommandOption{
 				{Type: discordgo.ApplicationCommandOptionString, Name: "name", Description: "Channel Name", Required: true},
-				{Type: discordgo.ApplicationCommandOptionString, Name: "channel", Description: "Channel (# or ID)", Required: true},
-				{Type: discordgo.ApplicationCommandOptionString, Name: "role", Description: "Access Role (@ or ID)", Required: true},
-				{Type: discordgo.ApplicationCommandOptionString, Name: "notificationrole", Description: "Notification Role (@ or ID)", Required: true},
+				{Type: discordgo.ApplicationCommandOptionString, Name: "channel", Description: "Channel (# or ID)", Required: false},
+				{Type: discordgo.ApplicationCommandOptionString, Name: "role", Description: "Access Role (@ or ID)", Required: false},
+				{Type: discordgo.ApplicationCommandOptionString, Name: "notificationrole", Description: "Notification Role (@ or ID)", Required: false},
+				{Type: discordgo.ApplicationCommandOptionBoolean, Name: "create", Description: "Create the channel and both roles instead of giving IDs", Required: false},
+				{
+					Type:         discordgo.ApplicationCommandOptionChannel,
+					Name:         "category",
+					Description:  "Category for the created channel",
+					Required:     false,
+					ChannelTypes: []discordgo.ChannelType{discordgo.ChannelTypeGuildCategory},
+				},
+			},
+		},
+		{
+			Name:        "unsubscribeall",
+			Description: "Leave every notification channel and digest you're subscribed to.",
+		},
+		{
+			Name:                     "announce",
+			Description:              "Compose an announcement for a notification channel.",
+			DefaultMemberPermissions: &staffPermissions,
+			Options: []*discordgo.ApplicationCommandOption{
+				{Type: discordgo.ApplicationCommandOptionString, Name: "channel", Description: "Notification channel", Required: true, Autocomplete: true},
+				{Type: discordgo.ApplicationCommandOptionString, Name: "post_at", Description: "Schedule for later (YYYY-MM-DD HH:MM, UTC)", Required: false},
+			},
+		},
+		{
+			Name:                     "addfeed",
+			Description:              "Post new entries from an RSS or Atom feed to a notification channel.",
+			DefaultMemberPermissions: &staffPermissions,
+			Options: []*discordgo.ApplicationCommandOption{
+				{Type: discordgo.ApplicationCommandOptionString, Name: "channel", Description: "Notification channel", Required: true, Autocomplete: true},
+				{Type: discordgo.ApplicationCommandOptionString, Name: "url", Description: "Feed URL (http or https)", Required: true},
+				{Type: discordgo.ApplicationCommandOptionString, Name: "include", Description: "Only post entries mentioning one of these comma-separated keywords", Required: false},
+				{Type: discordgo.ApplicationCommandOptionString, Name: "exclude", Description: "Skip entries mentioning any of these comma-separated keywords", Required: false},
+				{Type: discordgo.ApplicationCommandOptionInteger, Name: "interval", Description: "Minutes between checks (default 15)", Required: false, MinValue: floatPtr(1), MaxValue: 1440},
+				{Type: discordgo.ApplicationCommandOptionInteger, Name: "max_items", Description: "Most entries posted per check (default 5)", Required: false, MinValue: floatPtr(1), MaxValue: maxFeedMaxItems},
+			},
+		},
+		{
+			Name:                     "delfeed",
+			Description:              "Stop posting a feed to a notification channel.",
+			DefaultMemberPermissions: &staffPermissions,
+			Options: []*discordgo.ApplicationCommandOption{
+				{Type: discordgo.ApplicationCommandOptionString, Name: "channel", Description: "Notification channel", Required: true, Autocomplete: true},
+				{Type: discordgo.ApplicationCommandOptionString, Name: "url", Description: "Feed URL", Required: true},
 			},
 		},
 		{
-			Name:        "delnotificationchannel",
-			Description: "Delete a notification channel by name.",
+			Name:                     "webhooksecret",
+			Description:              "Create or rotate the webhook secret for a notification channel.",
+			DefaultMemberPermissions: &adminPermissions,
+			Options: []*discordgo.ApplicationCommandOption{
+				{Type: discordgo.ApplicationCommandOptionString, Name: "channel", Description: "Notification channel", Required: true, Autocomplete: true},
+				{Type: discordgo.ApplicationCommandOptionBoolean, Name: "disable", Description: "Remove the secret and turn the webhook off", Required: false},
+			},
+		},
+		{
+			Name:                     "editnotificationchannel",
+			Description:              "Change how a notification channel is listed on the panel.",
+			DefaultMemberPermissions: &adminPermissions,
+			Options: []*discordgo.ApplicationCommandOption{
+				{Type: discordgo.ApplicationCommandOptionString, Name: "channel", Description: "Notification channel", Required: true, Autocomplete: true},
+				{Type: discordgo.ApplicationCommandOptionString, Name: "description", Description: "What the channel is for (\"none\" to clear)", Required: false, MaxLength: maxNotificationDescriptionLength},
+				{Type: discordgo.ApplicationCommandOptionString, Name: "emoji", Description: "Emoji for the panel button (\"none\" to clear)", Required: false},
+				{Type: discordgo.ApplicationCommandOptionInteger, Name: "order", Description: "Position on the panel; lower comes first", Required: false},
+			},
+		},
+		{
+			Name:                     "delnotificationchannel",
+			Description:              "Delete a notification channel by name.",
+			DefaultMemberPermissions: &adminPermissions,
 			Options: []*discordgo.ApplicationCommandOption{
 				{Type: discordgo.ApplicationCommandOptionString, Name: "name", Description: "Channel Name", Required: true},
+				{Type: discordgo.ApplicationCommandOptionBoolean, Name: "teardown", Description: "Also delete the channel and roles the bot created", Required: false},
 			},
 		},
 	}
@@ -433,4 +789,24 @@ func registerCommands(s *discordgo.Session) {
 		}
 		log.Printf("Registered command '%s' successfully.", cmd.Name)
 	}
+
+	// Global commands, so members can also use them from DMs with the bot
+	dmPermission := true
+	globalCommands := []*discordgo.ApplicationCommand{
+		{
+			Name:         "partner",
+			Description:  "Look up a partner offering.",
+			DMPermission: &dmPermission,
+			Options: []*discordgo.ApplicationCommandOption{
+				{Type: discordgo.ApplicationCommandOptionString, Name: "name", Description: "Partner name, tag or keyword", Required: true, Autocomplete: true},
+			},
+		},
+	}
+	for _, cmd := range globalCommands {
+		if _, err := s.ApplicationCommandCreate(s.State.User.ID, "", cmd); err != nil {
+			log.Printf("Error registering global command '%s': %v", cmd.Name, err)
+			continue
+		}
+		log.Printf("Registered global command '%s' successfully.", cmd.Name)
+	}
 }
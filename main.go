@@ -20,6 +20,12 @@ func main() {
 	if err := loadConfig("config.json"); err != nil {
 		log.Fatalf("Error loading config file: %v", err)
 	}
+	if err := checkRedirectConfig(); err != nil {
+		log.Fatalf("Error in config file: %v", err)
+	}
+	if err := checkSpotlightConfig(); err != nil {
+		log.Fatalf("Error in config file: %v", err)
+	}
 
 	log.Println("Initializing Google Sheets API...")
 	authJSON, err := os.ReadFile(config.CredentialsPath)
@@ -40,10 +46,50 @@ func main() {
 	if err := loadPartners(); err != nil {
 		log.Fatalf("Error loading partners: %v", err)
 	}
+	// Load partner revision history from file
+	if err := loadPartnerHistory(); err != nil {
+		log.Fatalf("Error loading partner history: %v", err)
+	}
+	// Load partner proposals from file
+	if err := loadPartnerProposals(); err != nil {
+		log.Fatalf("Error loading partner proposals: %v", err)
+	}
+	// Load partner redemption codes from file
+	if err := loadPartnerCodes(); err != nil {
+		log.Fatalf("Error loading partner codes: %v", err)
+	}
+	// Load sent partner reminders from file
+	if err := loadPartnerReminders(); err != nil {
+		log.Fatalf("Error loading partner reminders: %v", err)
+	}
+	// Load spotlight rotation state from file
+	if err := loadSpotlight(); err != nil {
+		log.Fatalf("Error loading spotlight state: %v", err)
+	}
+	// Load the salt used to anonymise partner analytics
+	if err := loadAnalyticsSalt(); err != nil {
+		log.Fatalf("Error loading analytics salt: %v", err)
+	}
 	// Load notification channels from file
 	if err := loadNotificationChannels(); err != nil {
 		log.Fatalf("Error loading notification channels: %v", err)
 	}
+	// Load scheduled notification announcements from file
+	if err := loadScheduledAnnouncements(); err != nil {
+		log.Fatalf("Error loading scheduled announcements: %v", err)
+	}
+	// Load notification feed poll state from file
+	if err := loadFeedState(); err != nil {
+		log.Fatalf("Error loading feed state: %v", err)
+	}
+	// Load digest subscriptions and queued digest messages from file
+	if err := loadDigests(); err != nil {
+		log.Fatalf("Error loading digests: %v", err)
+	}
+	// Load recently used webhook idempotency keys from file
+	if err := loadWebhookKeys(); err != nil {
+		log.Fatalf("Error loading webhook idempotency keys: %v", err)
+	}
 
 	log.Println("Creating Discord session...")
 	dg, err := discordgo.New("Bot " + config.BotToken)
@@ -56,6 +102,7 @@ func main() {
 	log.Println("Adding event handlers...")
 	dg.AddHandler(onMessageCreate)
 	dg.AddHandler(onInteractionCreate)
+	dg.AddHandler(onDigestMessageCreate)
 
 	log.Println("Connecting to Discord...")
 	if err := dg.Open(); err != nil {
@@ -63,9 +110,14 @@ func main() {
 	}
 	defer dg.Close()
 
+	startHTTPServer(dg)
+
 	log.Println("Registering commands...")
 	registerCommands(dg)
 
+	log.Println("Checking configured roles...")
+	checkConfiguredRoles(dg)
+
 	// --- Only send embeds if not already present ---
 	botUser, err := dg.User("@me")
 	if err != nil {
@@ -78,15 +130,18 @@ func main() {
 	if !found {
 		sendPartnersEmbed(dg)
 	}
-	foundNotif, err := hasBotEmbedInChannel(dg, notificationsChannelID, botUser.ID)
-	if err != nil {
-		log.Printf("Error checking for existing notifications embed: %v", err)
-	}
-	if !foundNotif {
-		sendNotificationsEmbed(dg)
+	if _, err := refreshSubscriberCounts(dg); err != nil {
+		log.Printf("Error counting notification subscribers: %v", err)
 	}
+	updateNotificationsEmbed(dg)
 	// ------------------------------------------------
 
+	startPartnerScheduler(dg)
+	startAnnouncementScheduler(dg)
+	startFeedPoller(dg)
+	startDigestScheduler(dg)
+	startNotificationsPanelRefresher(dg)
+
 	fmt.Println("Bot is now running. Press Ctrl+C to exit.")
 
 	stop := make(chan os.Signal, 1)
@@ -0,0 +1,367 @@
+package main
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"log"
+	"os"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/bwmarrin/discordgo"
+)
+
+// --- Notification Announcements Section ---
+
+const (
+	scheduledAnnouncementsFile = "scheduled_announcements.json"
+	announcementDraftTTL       = 30 * time.Minute
+	announcementInterval       = time.Minute
+	maxAnnouncementTitleLength = 256
+)
+
+// An announcement composed with /announce, posted now or at PostAt
+type NotificationAnnouncement struct {
+	ID       string    `json:"id"`
+	Channel  string    `json:"channel"`
+	Title    string    `json:"title"`
+	Body     string    `json:"body"`
+	ImageURL string    `json:"image_url,omitempty"`
+	AuthorID string    `json:"author_id"`
+	PostAt   time.Time `json:"post_at,omitempty"`
+}
+
+// An announcement waiting for the modal or the preview to be confirmed
+type announcementDraft struct {
+	NotificationAnnouncement
+	Expires time.Time
+}
+
+var (
+	scheduledAnnouncements   []NotificationAnnouncement
+	scheduledAnnouncementsMu sync.Mutex
+
+	announcementDrafts   = map[string]*announcementDraft{}
+	announcementDraftsMu sync.Mutex
+)
+
+// Load scheduled announcements from file
+func loadScheduledAnnouncements() error {
+	b, err := os.ReadFile(scheduledAnnouncementsFile)
+	if err != nil {
+		if os.IsNotExist(err) {
+			scheduledAnnouncements = []NotificationAnnouncement{}
+			return nil
+		}
+		return err
+	}
+	return json.Unmarshal(b, &scheduledAnnouncements)
+}
+
+// Save scheduled announcements to file. Callers must hold scheduledAnnouncementsMu.
+func saveScheduledAnnouncements() error {
+	b, err := json.MarshalIndent(scheduledAnnouncements, "", "  ")
+	if err != nil {
+		return err
+	}
+	return writeFileAtomic(scheduledAnnouncementsFile, b, 0644)
+}
+
+// Build the embed posted to the notification channel
+func announcementEmbed(a *NotificationAnnouncement) *discordgo.MessageEmbed {
+	embed := &discordgo.MessageEmbed{
+		Title:       a.Title,
+		Description: a.Body,
+	}
+	if a.ImageURL != "" {
+		embed.Image = &discordgo.MessageEmbedImage{URL: a.ImageURL}
+	}
+	return embed
+}
+
+// Post an announcement to its channel, pinging only that channel's notification role
+func postNotificationAnnouncement(s *discordgo.Session, a *NotificationAnnouncement) error {
+	nc, ok := findNotificationChannel(a.Channel)
+	if !ok {
+		return fmt.Errorf("notification channel %q no longer exists", a.Channel)
+	}
+	msg := &discordgo.MessageSend{
+		Embeds:          []*discordgo.MessageEmbed{announcementEmbed(a)},
+		AllowedMentions: &discordgo.MessageAllowedMentions{},
+	}
+	if nc.NotificationRoleID != "" {
+		msg.Content = "<@&" + nc.NotificationRoleID + ">"
+		msg.AllowedMentions.Roles = []string{nc.NotificationRoleID}
+	}
+	_, err := s.ChannelMessageSendComplex(nc.ChannelID, msg)
+	return err
+}
+
+// Handle autocomplete for the notification channel option of the notification commands
+func handleNotificationChannelAutocomplete(s *discordgo.Session, i *discordgo.InteractionCreate) {
+	var query string
+	for _, opt := range i.ApplicationCommandData().Options {
+		if opt.Name == "channel" && opt.Focused {
+			query = strings.ToLower(strings.TrimSpace(opt.StringValue()))
+		}
+	}
+	choices := []*discordgo.ApplicationCommandOptionChoice{}
+	for _, nc := range notificationChannelsSnapshot() {
+		if len(choices) == maxAutocompleteChoices {
+			break
+		}
+		if strings.Contains(strings.ToLower(nc.Name), query) {
+			choices = append(choices, &discordgo.ApplicationCommandOptionChoice{Name: nc.Name, Value: nc.Name})
+		}
+	}
+	s.InteractionRespond(i.Interaction, &discordgo.InteractionResponse{
+		Type: discordgo.InteractionApplicationCommandAutocompleteResult,
+		Data: &discordgo.InteractionResponseData{Choices: choices},
+	})
+}
+
+// Handle /announce: open the composer modal for a notification channel
+func handleAnnounceCommand(s *discordgo.Session, i *discordgo.InteractionCreate) {
+	if !isAdministrator(i) {
+		respondEphemeral(s, i, "You do not have permission to use this command.")
+		return
+	}
+	opts := optionMap(i.ApplicationCommandData().Options)
+	nc, ok := findNotificationChannel(opts["channel"].StringValue())
+	if !ok {
+		respondEphemeral(s, i, "Notification channel not found. Pick one from the suggestions.")
+		return
+	}
+	draft := &announcementDraft{
+		NotificationAnnouncement: NotificationAnnouncement{Channel: nc.Name, AuthorID: i.Member.User.ID},
+		Expires:                  time.Now().Add(announcementDraftTTL),
+	}
+	if opt, ok := opts["post_at"]; ok {
+		t, err := parsePartnerTime(opt.StringValue())
+		if err != nil {
+			respondEphemeral(s, i, "post_at: "+err.Error())
+			return
+		}
+		if !t.After(time.Now()) {
+			respondEphemeral(s, i, "post_at must be in the future. Leave it out to post straight away.")
+			return
+		}
+		draft.PostAt = t
+	}
+
+	idBytes := make([]byte, 8)
+	rand.Read(idBytes)
+	draft.ID = hex.EncodeToString(idBytes)
+	announcementDraftsMu.Lock()
+	for key, pending := range announcementDrafts {
+		if time.Now().After(pending.Expires) {
+			delete(announcementDrafts, key)
+		}
+	}
+	announcementDrafts[draft.ID] = draft
+	announcementDraftsMu.Unlock()
+
+	s.InteractionRespond(i.Interaction, &discordgo.InteractionResponse{
+		Type: discordgo.InteractionResponseModal,
+		Data: &discordgo.InteractionResponseData{
+			CustomID: "announcemodal_" + draft.ID,
+			Title:    truncateRunes("Announce in "+nc.Name, 45),
+			Components: []discordgo.MessageComponent{
+				discordgo.ActionsRow{Components: []discordgo.MessageComponent{
+					discordgo.TextInput{
+						CustomID:  "title",
+						Label:     "Title",
+						Style:     discordgo.TextInputShort,
+						Required:  true,
+						MaxLength: maxAnnouncementTitleLength,
+					},
+				}},
+				discordgo.ActionsRow{Components: []discordgo.MessageComponent{
+					discordgo.TextInput{
+						CustomID:  "body",
+						Label:     "Body",
+						Style:     discordgo.TextInputParagraph,
+						Required:  true,
+						MaxLength: 4000,
+					},
+				}},
+				discordgo.ActionsRow{Components: []discordgo.MessageComponent{
+					discordgo.TextInput{
+						CustomID:    "image",
+						Label:       "Image URL (optional)",
+						Style:       discordgo.TextInputShort,
+						Placeholder: "https://...",
+					},
+				}},
+			},
+		},
+	})
+}
+
+// Handle the composer modal: store the content and show a preview
+func handleAnnounceModal(s *discordgo.Session, i *discordgo.InteractionCreate) {
+	data := i.ModalSubmitData()
+	id := strings.TrimPrefix(data.CustomID, "announcemodal_")
+	values := modalValues(data)
+
+	announcementDraftsMu.Lock()
+	draft, ok := announcementDrafts[id]
+	if ok && draft.AuthorID == i.Member.User.ID {
+		draft.Title = strings.TrimSpace(values["title"])
+		draft.Body = strings.TrimSpace(values["body"])
+		draft.ImageURL = strings.TrimSpace(values["image"])
+		draft.Expires = time.Now().Add(announcementDraftTTL)
+	}
+	announcementDraftsMu.Unlock()
+
+	if !ok || draft.AuthorID != i.Member.User.ID {
+		respondEphemeral(s, i, "This announcement has expired. Run /announce again.")
+		return
+	}
+	if draft.ImageURL != "" {
+		if problem := validateHTTPURL(draft.ImageURL); problem != "" {
+			respondEphemeral(s, i, "Image URL "+problem+". Run /announce again.")
+			return
+		}
+	}
+	nc, ok := findNotificationChannel(draft.Channel)
+	if !ok {
+		respondEphemeral(s, i, "That notification channel was removed. Run /announce again.")
+		return
+	}
+
+	when := "straight away"
+	if !draft.PostAt.IsZero() {
+		when = fmt.Sprintf("<t:%d:f> (<t:%d:R>)", draft.PostAt.Unix(), draft.PostAt.Unix())
+	}
+	ping := "nobody (this channel has no notification role)"
+	if nc.NotificationRoleID != "" {
+		ping = "<@&" + nc.NotificationRoleID + ">"
+	}
+	postLabel := "Post Now"
+	if !draft.PostAt.IsZero() {
+		postLabel = "Schedule"
+	}
+	s.InteractionRespond(i.Interaction, &discordgo.InteractionResponse{
+		Type: discordgo.InteractionResponseChannelMessageWithSource,
+		Data: &discordgo.InteractionResponseData{
+			Content: fmt.Sprintf("**Preview** — posts in <#%s> %s and pings %s.", nc.ChannelID, when, ping),
+			Embeds:  []*discordgo.MessageEmbed{announcementEmbed(&draft.NotificationAnnouncement)},
+			Flags:   discordgo.MessageFlagsEphemeral,
+			// The preview never pings; only the real post does
+			AllowedMentions: &discordgo.MessageAllowedMentions{},
+			Components: []discordgo.MessageComponent{
+				discordgo.ActionsRow{Components: []discordgo.MessageComponent{
+					discordgo.Button{Label: postLabel, CustomID: "announcement_confirm_" + id, Style: discordgo.SuccessButton},
+					discordgo.Button{Label: "Cancel", CustomID: "announcement_cancel_" + id, Style: discordgo.SecondaryButton},
+				}},
+			},
+		},
+	})
+}
+
+// Handle the Post/Schedule and Cancel buttons on a preview
+func handleAnnouncementButton(s *discordgo.Session, i *discordgo.InteractionCreate) {
+	customID := i.MessageComponentData().CustomID
+	confirm := strings.HasPrefix(customID, "announcement_confirm_")
+	id := strings.TrimPrefix(strings.TrimPrefix(customID, "announcement_confirm_"), "announcement_cancel_")
+
+	announcementDraftsMu.Lock()
+	draft, ok := announcementDrafts[id]
+	if ok && draft.AuthorID == i.Member.User.ID {
+		delete(announcementDrafts, id)
+	}
+	announcementDraftsMu.Unlock()
+
+	update := func(content string) {
+		s.InteractionRespond(i.Interaction, &discordgo.InteractionResponse{
+			Type: discordgo.InteractionResponseUpdateMessage,
+			Data: &discordgo.InteractionResponseData{
+				Content:         content,
+				Embeds:          []*discordgo.MessageEmbed{},
+				Components:      []discordgo.MessageComponent{},
+				AllowedMentions: &discordgo.MessageAllowedMentions{},
+			},
+		})
+	}
+	switch {
+	case !ok || time.Now().After(draft.Expires):
+		update("This announcement has expired. Run /announce again.")
+		return
+	case draft.AuthorID != i.Member.User.ID:
+		respondEphemeral(s, i, "Only the person who composed this announcement can post it.")
+		return
+	case !confirm:
+		update("Announcement cancelled.")
+		return
+	}
+
+	a := draft.NotificationAnnouncement
+	if !a.PostAt.IsZero() && a.PostAt.After(time.Now()) {
+		scheduledAnnouncementsMu.Lock()
+		scheduledAnnouncements = append(scheduledAnnouncements, a)
+		err := saveScheduledAnnouncements()
+		if err != nil {
+			scheduledAnnouncements = scheduledAnnouncements[:len(scheduledAnnouncements)-1]
+		}
+		scheduledAnnouncementsMu.Unlock()
+		if err != nil {
+			log.Printf("Error saving scheduled announcements: %v", err)
+			update("Failed to schedule the announcement.")
+			return
+		}
+		update(fmt.Sprintf("🗓️ Announcement scheduled for %s <t:%d:f> (<t:%d:R>).", a.Channel, a.PostAt.Unix(), a.PostAt.Unix()))
+		return
+	}
+	if err := postNotificationAnnouncement(s, &a); err != nil {
+		log.Printf("Error posting announcement to %s: %v", a.Channel, err)
+		update("Failed to post the announcement: " + err.Error())
+		return
+	}
+	update("📣 Announcement posted to " + a.Channel + ".")
+}
+
+// Post scheduled announcements that are due
+func publishScheduledAnnouncements(s *discordgo.Session, now time.Time) {
+	scheduledAnnouncementsMu.Lock()
+	var due []NotificationAnnouncement
+	remaining := scheduledAnnouncements[:0]
+	for _, a := range scheduledAnnouncements {
+		if a.PostAt.After(now) {
+			remaining = append(remaining, a)
+			continue
+		}
+		due = append(due, a)
+	}
+	if len(due) == 0 {
+		scheduledAnnouncementsMu.Unlock()
+		return
+	}
+	scheduledAnnouncements = remaining
+	if err := saveScheduledAnnouncements(); err != nil {
+		log.Printf("Error saving scheduled announcements: %v", err)
+	}
+	scheduledAnnouncementsMu.Unlock()
+
+	for _, a := range due {
+		if err := postNotificationAnnouncement(s, &a); err != nil {
+			log.Printf("Error posting scheduled announcement to %s: %v", a.Channel, err)
+			sendAdminAlert(s, fmt.Sprintf("⚠️ The scheduled announcement %q for %s could not be posted: %v", a.Title, a.Channel, err))
+		}
+	}
+}
+
+// Start the background announcement scheduler
+func startAnnouncementScheduler(s *discordgo.Session) {
+	publishScheduledAnnouncements(s, time.Now())
+	go func() {
+		ticker := time.NewTicker(announcementInterval)
+		defer ticker.Stop()
+		for now := range ticker.C {
+			publishScheduledAnnouncements(s, now)
+		}
+	}()
+}
@@ -0,0 +1,414 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"log"
+	"os"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/bwmarrin/discordgo"
+)
+
+// --- Notification Digests Section ---
+
+const (
+	digestSubscriptionsFile = "digest_subscriptions.json"
+	digestQueueFile         = "digest_queue.json"
+	digestInterval          = 10 * time.Minute
+	digestSummaryLength     = 100
+	maxDigestEmbedFields    = 25
+	digestFooterReserve     = 100 // Room for the footer naming channels that didn't fit
+
+	digestDaily  = "daily"
+	digestWeekly = "weekly"
+)
+
+// A member's digest subscriptions: channel name to cadence, and when each
+// cadence's digest was last sent
+type DigestSubscription struct {
+	Channels map[string]string    `json:"channels"`
+	LastSent map[string]time.Time `json:"last_sent"`
+}
+
+// A message posted in a notification channel, waiting to go out in digests
+type digestItem struct {
+	Channel   string    `json:"channel"`
+	ChannelID string    `json:"channel_id"`
+	MessageID string    `json:"message_id"`
+	Summary   string    `json:"summary"`
+	Time      time.Time `json:"time"`
+}
+
+var (
+	// Keyed by user ID
+	digestSubscriptions = map[string]*DigestSubscription{}
+	digestQueue         []digestItem
+	digestMu            sync.Mutex
+)
+
+// How often a digest cadence is delivered
+func digestPeriod(cadence string) time.Duration {
+	if cadence == digestWeekly {
+		return 7 * 24 * time.Hour
+	}
+	return 24 * time.Hour
+}
+
+// Load digest subscriptions and queued messages from file
+func loadDigests() error {
+	if b, err := os.ReadFile(digestSubscriptionsFile); err == nil {
+		if err := json.Unmarshal(b, &digestSubscriptions); err != nil {
+			return err
+		}
+	} else if !os.IsNotExist(err) {
+		return err
+	}
+	if b, err := os.ReadFile(digestQueueFile); err == nil {
+		return json.Unmarshal(b, &digestQueue)
+	} else if !os.IsNotExist(err) {
+		return err
+	}
+	return nil
+}
+
+// Save digest subscriptions to file. Callers must hold digestMu.
+func saveDigestSubscriptions() error {
+	b, err := json.MarshalIndent(digestSubscriptions, "", "  ")
+	if err != nil {
+		return err
+	}
+	return writeFileAtomic(digestSubscriptionsFile, b, 0644)
+}
+
+// Save queued digest messages to file. Callers must hold digestMu.
+func saveDigestQueue() error {
+	b, err := json.MarshalIndent(digestQueue, "", "  ")
+	if err != nil {
+		return err
+	}
+	return writeFileAtomic(digestQueueFile, b, 0644)
+}
+
+// A member's digest cadence for a channel, or "" when they don't get one
+func digestCadence(userID, channel string) string {
+	digestMu.Lock()
+	defer digestMu.Unlock()
+	if sub := digestSubscriptions[userID]; sub != nil {
+		return sub.Channels[channel]
+	}
+	return ""
+}
+
+// Set or clear (cadence "") a member's digest for a channel
+func setDigestCadence(userID, channel, cadence string, now time.Time) error {
+	digestMu.Lock()
+	defer digestMu.Unlock()
+	sub := digestSubscriptions[userID]
+	if sub == nil {
+		if cadence == "" {
+			return nil
+		}
+		sub = &DigestSubscription{Channels: map[string]string{}, LastSent: map[string]time.Time{}}
+		digestSubscriptions[userID] = sub
+	}
+	if cadence == "" {
+		delete(sub.Channels, channel)
+	} else {
+		sub.Channels[channel] = cadence
+		// Start the clock on first subscribe so the first digest isn't sent immediately
+		if sub.LastSent[cadence].IsZero() {
+			sub.LastSent[cadence] = now
+		}
+	}
+	if len(sub.Channels) == 0 {
+		delete(digestSubscriptions, userID)
+	}
+	return saveDigestSubscriptions()
+}
+
+// Clear all of a member's digests, returning the channel names they covered
+func clearDigests(userID string) ([]string, error) {
+	digestMu.Lock()
+	defer digestMu.Unlock()
+	sub := digestSubscriptions[userID]
+	if sub == nil {
+		return nil, nil
+	}
+	var names []string
+	for name := range sub.Channels {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	delete(digestSubscriptions, userID)
+	return names, saveDigestSubscriptions()
+}
+
+// Describe a member's digest for the subscription menu
+func describeDigestState(cadence string) string {
+	if cadence == "" {
+		return "📭 You don't get a digest for this channel."
+	}
+	return fmt.Sprintf("📰 You get a %s digest of this channel by DM.", cadence)
+}
+
+// Build the digest row for the subscription menu
+func digestButtonsRow(cadence, channel string) discordgo.ActionsRow {
+	return discordgo.ActionsRow{
+		Components: []discordgo.MessageComponent{
+			discordgo.Button{
+				Label:    "Daily Digest",
+				CustomID: "digest_daily_" + channel,
+				Style:    discordgo.SecondaryButton,
+				Emoji:    &discordgo.ComponentEmoji{Name: "📰"},
+				Disabled: cadence == digestDaily,
+			},
+			discordgo.Button{
+				Label:    "Weekly Digest",
+				CustomID: "digest_weekly_" + channel,
+				Style:    discordgo.SecondaryButton,
+				Emoji:    &discordgo.ComponentEmoji{Name: "🗞️"},
+				Disabled: cadence == digestWeekly,
+			},
+			discordgo.Button{
+				Label:    "Stop Digest",
+				CustomID: "digest_off_" + channel,
+				Style:    discordgo.DangerButton,
+				Disabled: cadence == "",
+			},
+		},
+	}
+}
+
+// Handle the digest buttons in the subscription menu
+func handleDigestButton(s *discordgo.Session, i *discordgo.InteractionCreate) {
+	cadence, name, _ := strings.Cut(strings.TrimPrefix(i.MessageComponentData().CustomID, "digest_"), "_")
+	nc, ok := findNotificationChannel(name)
+	if !ok {
+		respondEphemeral(s, i, "Notification channel not found.")
+		return
+	}
+	description := fmt.Sprintf("📰 You'll get a %s digest of %s by DM. Make sure you accept DMs from server members.", cadence, nc.Name)
+	switch cadence {
+	case digestDaily, digestWeekly:
+	case "off":
+		cadence = ""
+		description = fmt.Sprintf("📭 You'll no longer get digests of %s.", nc.Name)
+	default:
+		return
+	}
+	// Digest links only open for members who can see the channel
+	if cadence != "" && !hasRole(i.Member.Roles, nc.AccessRoleID) {
+		if err := s.GuildMemberRoleAdd(config.GuildID, i.Member.User.ID, nc.AccessRoleID); err != nil {
+			log.Printf("Role assignment error: %v", err)
+			go explainRoleFailure(s, nc.Name+" digest", nc.AccessRoleID)
+			respondEphemeral(s, i, "Failed to assign roles. Please contact an admin.")
+			return
+		}
+		description += fmt.Sprintf("\n🔕 You've also been subscribed to %s without notifications so the links open.", nc.Name)
+	}
+	if err := setDigestCadence(i.Member.User.ID, nc.Name, cadence, time.Now()); err != nil {
+		log.Printf("Error saving digest subscriptions: %v", err)
+		respondEphemeral(s, i, "Failed to save your digest preference. Please contact an admin.")
+		return
+	}
+	s.InteractionRespond(i.Interaction, &discordgo.InteractionResponse{
+		Type: discordgo.InteractionResponseChannelMessageWithSource,
+		Data: &discordgo.InteractionResponseData{
+			Embeds: []*discordgo.MessageEmbed{{
+				Title:       "Subscription Updated",
+				Description: description,
+			}},
+			Flags: discordgo.MessageFlagsEphemeral,
+		},
+	})
+}
+
+// Summarise a message in one line for a digest
+func digestSummary(m *discordgo.Message) string {
+	text := m.Content
+	if len(m.Embeds) > 0 {
+		text = firstNonEmpty(m.Embeds[0].Title, m.Embeds[0].Description, text)
+	}
+	// Role pings on their own say nothing useful
+	if len(m.MentionRoles) > 0 && strings.HasPrefix(strings.TrimSpace(text), "<@&") && len(strings.Fields(text)) == 1 {
+		text = ""
+	}
+	text = strings.Join(strings.Fields(text), " ")
+	if text == "" {
+		if m.Author != nil {
+			return "New message from " + m.Author.Username
+		}
+		return "New message"
+	}
+	return truncateRunes(text, digestSummaryLength)
+}
+
+// Queue messages posted in notification channels for digests. Registered as
+// its own handler so bot posts (feeds, webhooks, announcements) are included.
+func onDigestMessageCreate(s *discordgo.Session, m *discordgo.MessageCreate) {
+	name := ""
+	notificationChannelsMu.Lock()
+	for _, nc := range notificationChannels {
+		if nc.ChannelID == m.ChannelID {
+			name = nc.Name
+			break
+		}
+	}
+	notificationChannelsMu.Unlock()
+	if name == "" {
+		return
+	}
+	digestMu.Lock()
+	defer digestMu.Unlock()
+	if len(digestSubscriptions) == 0 {
+		return
+	}
+	digestQueue = append(digestQueue, digestItem{
+		Channel:   name,
+		ChannelID: m.ChannelID,
+		MessageID: m.ID,
+		Summary:   digestSummary(m.Message),
+		Time:      m.Timestamp.UTC(),
+	})
+	if err := saveDigestQueue(); err != nil {
+		log.Printf("Error saving digest queue: %v", err)
+	}
+}
+
+// Build a digest embed from queued items, one field per channel. Channels that
+// would push the embed past Discord's size limits are counted in the footer.
+func digestEmbed(cadence string, items []digestItem) *discordgo.MessageEmbed {
+	byChannel := map[string][]digestItem{}
+	var channels []string
+	for _, item := range items {
+		if _, ok := byChannel[item.Channel]; !ok {
+			channels = append(channels, item.Channel)
+		}
+		byChannel[item.Channel] = append(byChannel[item.Channel], item)
+	}
+	sort.Strings(channels)
+
+	embed := &discordgo.MessageEmbed{
+		Title:       fmt.Sprintf("Your %s digest", cadence),
+		Description: fmt.Sprintf("%d new message(s) in your notification channels.", len(items)),
+		Timestamp:   time.Now().UTC().Format(time.RFC3339),
+	}
+	total := len(embed.Title) + len(embed.Description)
+	for n, name := range channels {
+		list := byChannel[name]
+		value := ""
+		for idx, item := range list {
+			line := fmt.Sprintf("• [%s](https://discord.com/channels/%s/%s/%s)\n",
+				strings.ReplaceAll(item.Summary, "]", ")"), config.GuildID, item.ChannelID, item.MessageID)
+			more := fmt.Sprintf("…and %d more", len(list)-idx)
+			if len(value)+len(line)+len(more) > maxEmbedFieldValueLength {
+				value += more
+				break
+			}
+			value += line
+		}
+		if len(embed.Fields) == maxDigestEmbedFields || total+len(name)+len(value) > maxEmbedTotalLength-digestFooterReserve {
+			embed.Footer = &discordgo.MessageEmbedFooter{
+				Text: fmt.Sprintf("…and %d more channel(s) that didn't fit in this digest", len(channels)-n),
+			}
+			break
+		}
+		total += len(name) + len(value)
+		embed.Fields = append(embed.Fields, &discordgo.MessageEmbedField{Name: name, Value: value})
+	}
+	return embed
+}
+
+// Send digests that are due and drop queued messages no digest still needs
+func sendDigests(s *discordgo.Session, now time.Time) {
+	type pendingDigest struct {
+		userID  string
+		cadence string
+		embed   *discordgo.MessageEmbed
+	}
+	var outgoing []pendingDigest
+
+	digestMu.Lock()
+	for userID, sub := range digestSubscriptions {
+		for _, cadence := range []string{digestDaily, digestWeekly} {
+			since := sub.LastSent[cadence]
+			if now.Sub(since) < digestPeriod(cadence) {
+				continue
+			}
+			var items []digestItem
+			for _, item := range digestQueue {
+				if sub.Channels[item.Channel] == cadence && item.Time.After(since) {
+					items = append(items, item)
+				}
+			}
+			// A digest with messages is only marked sent once the DM goes out
+			if len(items) == 0 {
+				sub.LastSent[cadence] = now
+			} else {
+				outgoing = append(outgoing, pendingDigest{userID, cadence, digestEmbed(cadence, items)})
+			}
+		}
+	}
+	// The oldest message any digest can still need is one weekly period back
+	cutoff := now.Add(-digestPeriod(digestWeekly))
+	kept := digestQueue[:0]
+	for _, item := range digestQueue {
+		if item.Time.After(cutoff) {
+			kept = append(kept, item)
+		}
+	}
+	digestQueue = kept
+	if err := saveDigestSubscriptions(); err != nil {
+		log.Printf("Error saving digest subscriptions: %v", err)
+	}
+	if err := saveDigestQueue(); err != nil {
+		log.Printf("Error saving digest queue: %v", err)
+	}
+	digestMu.Unlock()
+
+	var sent []pendingDigest
+	for _, d := range outgoing {
+		dm, err := s.UserChannelCreate(d.userID)
+		if err == nil {
+			_, err = s.ChannelMessageSendEmbed(dm.ID, d.embed)
+		}
+		if err != nil {
+			log.Printf("Error sending digest to %s: %v", d.userID, err)
+			// Members who don't accept DMs would fail every time; skip this digest
+			// rather than retrying it every interval
+			if !isPermanentDiscordError(err) {
+				continue
+			}
+		}
+		sent = append(sent, d)
+	}
+	if len(sent) == 0 {
+		return
+	}
+
+	digestMu.Lock()
+	defer digestMu.Unlock()
+	for _, d := range sent {
+		if sub := digestSubscriptions[d.userID]; sub != nil {
+			sub.LastSent[d.cadence] = now
+		}
+	}
+	if err := saveDigestSubscriptions(); err != nil {
+		log.Printf("Error saving digest subscriptions: %v", err)
+	}
+}
+
+// Start the background digest sender
+func startDigestScheduler(s *discordgo.Session) {
+	go func() {
+		ticker := time.NewTicker(digestInterval)
+		defer ticker.Stop()
+		for now := range ticker.C {
+			sendDigests(s, now)
+		}
+	}()
+}
@@ -0,0 +1,172 @@
+package main
+
+import (
+	"fmt"
+	"log"
+	"maps"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+	"unicode/utf8"
+
+	"github.com/bwmarrin/discordgo"
+)
+
+// --- Notification Directory Section ---
+
+const (
+	notificationsPanelRefreshInterval = 15 * time.Minute
+	maxNotificationDescriptionLength  = 100
+	guildMembersPageSize              = 1000
+)
+
+var (
+	// Members holding each role, refreshed periodically; nil until the first count
+	subscriberCounts   map[string]int
+	subscriberCountsMu sync.Mutex
+)
+
+// Notification channels in panel order: by Order, then name
+func sortedNotificationChannels() []NotificationChannel {
+	channels := notificationChannelsSnapshot()
+	sort.SliceStable(channels, func(a, b int) bool {
+		if channels[a].Order != channels[b].Order {
+			return channels[a].Order < channels[b].Order
+		}
+		return strings.ToLower(channels[a].Name) < strings.ToLower(channels[b].Name)
+	})
+	return channels
+}
+
+// Count members per role by paging through the guild member list.
+// Reports whether the counts changed since the last refresh.
+func refreshSubscriberCounts(s *discordgo.Session) (bool, error) {
+	counts := map[string]int{}
+	after := ""
+	for {
+		members, err := s.GuildMembers(config.GuildID, after, guildMembersPageSize)
+		if err != nil {
+			return false, err
+		}
+		for _, m := range members {
+			for _, roleID := range m.Roles {
+				counts[roleID]++
+			}
+		}
+		if len(members) < guildMembersPageSize {
+			break
+		}
+		after = members[len(members)-1].User.ID
+	}
+	subscriberCountsMu.Lock()
+	defer subscriberCountsMu.Unlock()
+	changed := !maps.Equal(counts, subscriberCounts)
+	subscriberCounts = counts
+	return changed, nil
+}
+
+// The directory lines for a panel page: emoji, name, description and counts
+func notificationsDirectory(channels []NotificationChannel) string {
+	subscriberCountsMu.Lock()
+	counts := subscriberCounts
+	subscriberCountsMu.Unlock()
+
+	var lines []string
+	for _, nc := range channels {
+		line := "**" + nc.Name + "**"
+		if nc.Emoji != "" {
+			line = nc.Emoji + " " + line
+		}
+		if nc.Description != "" {
+			line += " — " + nc.Description
+		}
+		if counts != nil {
+			line += fmt.Sprintf("\n👥 %d subscribed · 🔔 %d with notifications", counts[nc.AccessRoleID], counts[nc.NotificationRoleID])
+		}
+		lines = append(lines, line)
+	}
+	return strings.Join(lines, "\n\n")
+}
+
+// Recount subscribers and refresh the panel, skipping the edit when nothing changed
+func refreshNotificationsDirectory(s *discordgo.Session) {
+	changed, err := refreshSubscriberCounts(s)
+	if err != nil {
+		log.Printf("Error counting notification subscribers: %v", err)
+		return
+	}
+	if changed {
+		updateNotificationsEmbed(s)
+	}
+}
+
+// Start the background refresh of panel subscriber counts
+func startNotificationsPanelRefresher(s *discordgo.Session) {
+	go func() {
+		ticker := time.NewTicker(notificationsPanelRefreshInterval)
+		defer ticker.Stop()
+		for range ticker.C {
+			refreshNotificationsDirectory(s)
+		}
+	}()
+}
+
+// Handle /editnotificationchannel: change a channel's description, emoji or panel order
+func handleEditNotificationChannelCommand(s *discordgo.Session, i *discordgo.InteractionCreate) {
+	if !isAdministrator(i) {
+		respondEphemeral(s, i, "You do not have permission to use this command.")
+		return
+	}
+	opts := optionMap(i.ApplicationCommandData().Options)
+	nc, ok := findNotificationChannel(opts["channel"].StringValue())
+	if !ok {
+		respondEphemeral(s, i, "Notification channel not found. Pick one from the suggestions.")
+		return
+	}
+
+	// Checking a custom emoji and refreshing the panel can outlast the interaction deadline
+	s.InteractionRespond(i.Interaction, &discordgo.InteractionResponse{
+		Type: discordgo.InteractionResponseDeferredChannelMessageWithSource,
+		Data: &discordgo.InteractionResponseData{Flags: discordgo.MessageFlagsEphemeral},
+	})
+	reply := func(content string) {
+		s.InteractionResponseEdit(i.Interaction, &discordgo.WebhookEdit{Content: &content})
+	}
+	updated := nc
+	var problems []string
+	if opt, ok := opts["description"]; ok {
+		updated.Description = strings.TrimSpace(opt.StringValue())
+		if strings.EqualFold(updated.Description, "none") {
+			updated.Description = ""
+		}
+		if n := utf8.RuneCountInString(updated.Description); n > maxNotificationDescriptionLength {
+			problems = append(problems, fmt.Sprintf("**description**: is %d characters; the limit is %d", n, maxNotificationDescriptionLength))
+		}
+	}
+	if opt, ok := opts["emoji"]; ok {
+		updated.Emoji = strings.TrimSpace(opt.StringValue())
+		if strings.EqualFold(updated.Emoji, "none") {
+			updated.Emoji = ""
+		} else if problem := validatePartnerEmoji(s, updated.Emoji); problem != "" {
+			problems = append(problems, "**emoji**: "+problem)
+		}
+	}
+	if opt, ok := opts["order"]; ok {
+		updated.Order = int(opt.IntValue())
+	}
+	if len(problems) > 0 {
+		reply("The notification channel wasn't saved:\n• " + strings.Join(problems, "\n• "))
+		return
+	}
+
+	nc, err := updateNotificationChannel(nc.Name, func(c *NotificationChannel) {
+		c.Description, c.Emoji, c.Order = updated.Description, updated.Emoji, updated.Order
+	})
+	if err != nil {
+		reply("Failed to save notification channels: " + err.Error())
+		return
+	}
+	reply(nc.Name + " updated.\n\n" + notificationsDirectory([]NotificationChannel{nc}))
+	updateNotificationsEmbed(s)
+}
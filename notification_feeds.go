@@ -0,0 +1,564 @@
+package main
+
+import (
+	"encoding/json"
+	"encoding/xml"
+	"fmt"
+	"html"
+	"io"
+	"log"
+	"maps"
+	"net/http"
+	"os"
+	"regexp"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/bwmarrin/discordgo"
+)
+
+// --- Notification Feeds Section ---
+
+const (
+	feedStateFile              = "feed_state.json"
+	feedPollerInterval         = time.Minute
+	defaultFeedIntervalMinutes = 15
+	defaultFeedMaxItems        = 5
+	maxFeedMaxItems            = 10
+	maxFeedSize                = 5 << 20
+	feedSummaryLength          = 500
+	feedSeenRetention          = 30 * 24 * time.Hour
+)
+
+// An RSS or Atom feed whose new entries are posted to a notification channel
+type NotificationFeed struct {
+	URL             string   `json:"url"`
+	Include         []string `json:"include,omitempty"` // Post only entries mentioning one of these keywords
+	Exclude         []string `json:"exclude,omitempty"` // Skip entries mentioning any of these keywords
+	IntervalMinutes int      `json:"interval_minutes,omitempty"`
+	MaxItems        int      `json:"max_items,omitempty"` // Most entries posted per poll; the rest wait for the next one
+}
+
+// Poll state for one feed, kept across restarts so entries are only posted once
+type feedState struct {
+	LastPolled   time.Time            `json:"last_polled"`
+	ETag         string               `json:"etag,omitempty"`
+	LastModified string               `json:"last_modified,omitempty"`
+	Seen         map[string]time.Time `json:"seen"`
+}
+
+// A feed entry, normalised from RSS or Atom
+type feedItem struct {
+	Key       string
+	Title     string
+	Link      string
+	Summary   string
+	Published time.Time
+}
+
+var (
+	feedStates   = map[string]*feedState{}
+	feedStatesMu sync.Mutex
+	feedClient   = &http.Client{Timeout: 20 * time.Second}
+	htmlTagRe    = regexp.MustCompile(`<[^>]*>`)
+)
+
+// Poll interval for a feed
+func (f NotificationFeed) interval() time.Duration {
+	if f.IntervalMinutes > 0 {
+		return time.Duration(f.IntervalMinutes) * time.Minute
+	}
+	return defaultFeedIntervalMinutes * time.Minute
+}
+
+// Entries posted per poll for a feed
+func (f NotificationFeed) maxItems() int {
+	if f.MaxItems > 0 {
+		return f.MaxItems
+	}
+	return defaultFeedMaxItems
+}
+
+// Check an entry against the feed's keyword filters
+func (f NotificationFeed) accepts(item feedItem) bool {
+	text := strings.ToLower(item.Title + " " + item.Summary)
+	for _, word := range f.Exclude {
+		if strings.Contains(text, word) {
+			return false
+		}
+	}
+	if len(f.Include) == 0 {
+		return true
+	}
+	for _, word := range f.Include {
+		if strings.Contains(text, word) {
+			return true
+		}
+	}
+	return false
+}
+
+// State key for a feed; the same URL can feed several channels
+func feedStateKey(nc *NotificationChannel, f NotificationFeed) string {
+	return nc.Name + "|" + f.URL
+}
+
+// Load feed poll state from file
+func loadFeedState() error {
+	b, err := os.ReadFile(feedStateFile)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil
+		}
+		return err
+	}
+	return json.Unmarshal(b, &feedStates)
+}
+
+// Save feed poll state to file. Callers must hold feedStatesMu.
+func saveFeedState() error {
+	b, err := json.MarshalIndent(feedStates, "", "  ")
+	if err != nil {
+		return err
+	}
+	return writeFileAtomic(feedStateFile, b, 0644)
+}
+
+// XML shapes for RSS 2.0 and Atom documents
+type rssDocument struct {
+	Channel struct {
+		Items []struct {
+			Title       string `xml:"title"`
+			Link        string `xml:"link"`
+			GUID        string `xml:"guid"`
+			PubDate     string `xml:"pubDate"`
+			Description string `xml:"description"`
+		} `xml:"item"`
+	} `xml:"channel"`
+}
+
+type atomDocument struct {
+	Entries []struct {
+		Title string `xml:"title"`
+		ID    string `xml:"id"`
+		Links []struct {
+			Href string `xml:"href,attr"`
+			Rel  string `xml:"rel,attr"`
+		} `xml:"link"`
+		Published string `xml:"published"`
+		Updated   string `xml:"updated"`
+		Summary   string `xml:"summary"`
+		Content   string `xml:"content"`
+	} `xml:"entry"`
+}
+
+// Parse the dates feeds commonly use, returning the zero time when none match
+func parseFeedTime(raw string) time.Time {
+	raw = strings.TrimSpace(raw)
+	for _, layout := range []string{time.RFC3339, time.RFC1123Z, time.RFC1123, "Mon, 2 Jan 2006 15:04:05 -0700", "Mon, 2 Jan 2006 15:04:05 MST"} {
+		if t, err := time.Parse(layout, raw); err == nil {
+			return t
+		}
+	}
+	return time.Time{}
+}
+
+// Strip markup from a feed summary for use in an embed
+func plainFeedText(raw string) string {
+	text := html.UnescapeString(htmlTagRe.ReplaceAllString(raw, " "))
+	return strings.Join(strings.Fields(text), " ")
+}
+
+// Parse an RSS 2.0 or Atom document into entries
+func parseFeed(data []byte) ([]feedItem, error) {
+	var root struct {
+		XMLName xml.Name
+	}
+	if err := xml.Unmarshal(data, &root); err != nil {
+		return nil, fmt.Errorf("not a valid feed: %v", err)
+	}
+
+	items := []feedItem{}
+	switch root.XMLName.Local {
+	case "rss":
+		var doc rssDocument
+		if err := xml.Unmarshal(data, &doc); err != nil {
+			return nil, err
+		}
+		for _, it := range doc.Channel.Items {
+			items = append(items, feedItem{
+				Key:       firstNonEmpty(it.GUID, it.Link, it.Title),
+				Title:     strings.TrimSpace(it.Title),
+				Link:      strings.TrimSpace(it.Link),
+				Summary:   plainFeedText(it.Description),
+				Published: parseFeedTime(it.PubDate),
+			})
+		}
+	case "feed":
+		var doc atomDocument
+		if err := xml.Unmarshal(data, &doc); err != nil {
+			return nil, err
+		}
+		for _, e := range doc.Entries {
+			link := ""
+			for _, l := range e.Links {
+				if l.Rel == "" || l.Rel == "alternate" {
+					link = l.Href
+					break
+				}
+			}
+			items = append(items, feedItem{
+				Key:       firstNonEmpty(e.ID, link, e.Title),
+				Title:     strings.TrimSpace(e.Title),
+				Link:      strings.TrimSpace(link),
+				Summary:   plainFeedText(firstNonEmpty(e.Summary, e.Content)),
+				Published: parseFeedTime(firstNonEmpty(e.Published, e.Updated)),
+			})
+		}
+	default:
+		return nil, fmt.Errorf("unsupported feed type <%s>", root.XMLName.Local)
+	}
+	return items, nil
+}
+
+// Helper: Return the first non-blank string, trimmed
+func firstNonEmpty(values ...string) string {
+	for _, v := range values {
+		if v = strings.TrimSpace(v); v != "" {
+			return v
+		}
+	}
+	return ""
+}
+
+// Fetch a feed, using the saved validators so unchanged feeds cost a 304.
+// Returns nil items when the feed hasn't changed.
+func fetchFeed(url string, st *feedState) ([]feedItem, error) {
+	req, err := http.NewRequest(http.MethodGet, url, nil)
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("User-Agent", "bw4e-bot feed reader")
+	if st.ETag != "" {
+		req.Header.Set("If-None-Match", st.ETag)
+	}
+	if st.LastModified != "" {
+		req.Header.Set("If-Modified-Since", st.LastModified)
+	}
+	resp, err := feedClient.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode == http.StatusNotModified {
+		return nil, nil
+	}
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("fetch failed: %s", resp.Status)
+	}
+	data, err := io.ReadAll(io.LimitReader(resp.Body, maxFeedSize+1))
+	if err != nil {
+		return nil, err
+	}
+	if len(data) > maxFeedSize {
+		return nil, fmt.Errorf("feed is larger than %d MB", maxFeedSize>>20)
+	}
+	items, err := parseFeed(data)
+	if err != nil {
+		return nil, err
+	}
+	st.ETag = resp.Header.Get("ETag")
+	st.LastModified = resp.Header.Get("Last-Modified")
+	return items, nil
+}
+
+// Build the embed for a feed entry
+func feedItemEmbed(item feedItem) *discordgo.MessageEmbed {
+	embed := &discordgo.MessageEmbed{
+		Title:       truncateRunes(firstNonEmpty(item.Title, item.Link, "New post"), maxAnnouncementTitleLength),
+		Description: truncateRunes(item.Summary, feedSummaryLength),
+	}
+	if validateHTTPURL(item.Link) == "" {
+		embed.URL = item.Link
+	}
+	if !item.Published.IsZero() {
+		embed.Timestamp = item.Published.UTC().Format(time.RFC3339)
+	}
+	return embed
+}
+
+// Post a feed entry to a notification channel with the role ping
+func postFeedItem(s *discordgo.Session, nc *NotificationChannel, item feedItem) error {
+	msg := &discordgo.MessageSend{
+		Embeds:          []*discordgo.MessageEmbed{feedItemEmbed(item)},
+		AllowedMentions: &discordgo.MessageAllowedMentions{},
+	}
+	if nc.NotificationRoleID != "" {
+		msg.Content = "<@&" + nc.NotificationRoleID + ">"
+		msg.AllowedMentions.Roles = []string{nc.NotificationRoleID}
+	}
+	_, err := s.ChannelMessageSendComplex(nc.ChannelID, msg)
+	return err
+}
+
+// Poll one feed and post entries not seen before through post. Seen stays nil
+// until the first successful fetch, which only records what's already there
+// so adding a feed doesn't flood the channel.
+func pollFeed(f NotificationFeed, st *feedState, now time.Time, post func(feedItem) error) error {
+	items, err := fetchFeed(f.URL, st)
+	if err != nil {
+		return err
+	}
+	firstPoll := st.Seen == nil
+	if firstPoll {
+		st.Seen = map[string]time.Time{}
+	}
+	if items == nil {
+		return nil
+	}
+
+	current := map[string]bool{}
+	var fresh []feedItem
+	for _, item := range items {
+		if item.Key == "" {
+			continue
+		}
+		current[item.Key] = true
+		if _, seen := st.Seen[item.Key]; seen {
+			continue
+		}
+		if firstPoll || !f.accepts(item) {
+			st.Seen[item.Key] = now
+			continue
+		}
+		fresh = append(fresh, item)
+	}
+
+	// Feeds list newest first; post oldest first and leave the rest for the next poll
+	for idx, posted := len(fresh)-1, 0; idx >= 0 && posted < f.maxItems(); idx-- {
+		if err := post(fresh[idx]); err != nil {
+			// Fetch in full next time so the unposted entries aren't hidden behind a 304
+			st.ETag, st.LastModified = "", ""
+			return fmt.Errorf("posting %q: %w", fresh[idx].Title, err)
+		}
+		st.Seen[fresh[idx].Key] = now
+		posted++
+	}
+
+	// Forget entries that have dropped out of the feed once they're old enough
+	for key, seenAt := range st.Seen {
+		if !current[key] && now.Sub(seenAt) > feedSeenRetention {
+			delete(st.Seen, key)
+		}
+	}
+	return nil
+}
+
+// Poll every feed that is due. Fetching and posting happen on copies of the
+// feed state outside feedStatesMu, so /addfeed and /delfeed never wait on a
+// slow feed.
+func pollNotificationFeeds(s *discordgo.Session, now time.Time) {
+	type duePoll struct {
+		nc   *NotificationChannel
+		f    NotificationFeed
+		key  string
+		live *feedState
+		st   feedState
+	}
+	channels := notificationChannelsSnapshot()
+
+	var due []duePoll
+	feedStatesMu.Lock()
+	for idx := range channels {
+		nc := &channels[idx]
+		for _, f := range nc.Feeds {
+			key := feedStateKey(nc, f)
+			live := feedStates[key]
+			if live == nil {
+				live = &feedState{}
+				feedStates[key] = live
+			}
+			if now.Sub(live.LastPolled) < f.interval() {
+				continue
+			}
+			// A failed fetch also waits for the next interval
+			live.LastPolled = now
+			st := *live
+			st.Seen = maps.Clone(live.Seen)
+			due = append(due, duePoll{nc, f, key, live, st})
+		}
+	}
+	feedStatesMu.Unlock()
+	if len(due) == 0 {
+		return
+	}
+
+	for idx := range due {
+		d := &due[idx]
+		err := pollFeed(d.f, &d.st, now, func(item feedItem) error {
+			return postFeedItem(s, d.nc, item)
+		})
+		if err != nil {
+			log.Printf("Error polling feed %s for %s: %v", d.f.URL, d.nc.Name, err)
+		}
+	}
+
+	feedStatesMu.Lock()
+	defer feedStatesMu.Unlock()
+	for _, d := range due {
+		// Skip feeds that were removed or re-added while they were polled
+		if feedStates[d.key] == d.live {
+			*d.live = d.st
+		}
+	}
+	if err := saveFeedState(); err != nil {
+		log.Printf("Error saving feed state: %v", err)
+	}
+}
+
+// Start the background feed poller
+func startFeedPoller(s *discordgo.Session) {
+	go func() {
+		pollNotificationFeeds(s, time.Now())
+		ticker := time.NewTicker(feedPollerInterval)
+		defer ticker.Stop()
+		for now := range ticker.C {
+			pollNotificationFeeds(s, now)
+		}
+	}()
+}
+
+// Save the state of a newly added feed
+func rememberFeedState(nc *NotificationChannel, f NotificationFeed, st *feedState) {
+	feedStatesMu.Lock()
+	defer feedStatesMu.Unlock()
+	feedStates[feedStateKey(nc, f)] = st
+	if err := saveFeedState(); err != nil {
+		log.Printf("Error saving feed state: %v", err)
+	}
+}
+
+// Drop saved state for a feed that was removed
+func forgetFeedState(nc *NotificationChannel, f NotificationFeed) {
+	feedStatesMu.Lock()
+	defer feedStatesMu.Unlock()
+	delete(feedStates, feedStateKey(nc, f))
+	if err := saveFeedState(); err != nil {
+		log.Printf("Error saving feed state: %v", err)
+	}
+}
+
+// Handle /addfeed: attach an RSS or Atom feed to a notification channel
+func handleAddFeedCommand(s *discordgo.Session, i *discordgo.InteractionCreate) {
+	if !isAdministrator(i) {
+		respondEphemeral(s, i, "You do not have permission to use this command.")
+		return
+	}
+	opts := optionMap(i.ApplicationCommandData().Options)
+	nc, ok := findNotificationChannel(opts["channel"].StringValue())
+	if !ok {
+		respondEphemeral(s, i, "Notification channel not found. Pick one from the suggestions.")
+		return
+	}
+	f := NotificationFeed{URL: strings.TrimSpace(opts["url"].StringValue())}
+	if problem := validateHTTPURL(f.URL); problem != "" {
+		respondEphemeral(s, i, "url "+problem+".")
+		return
+	}
+	for _, existing := range nc.Feeds {
+		if existing.URL == f.URL {
+			respondEphemeral(s, i, "That feed is already posting to "+nc.Name+".")
+			return
+		}
+	}
+	if opt, ok := opts["include"]; ok {
+		f.Include = parseTags(opt.StringValue())
+	}
+	if opt, ok := opts["exclude"]; ok {
+		f.Exclude = parseTags(opt.StringValue())
+	}
+	if opt, ok := opts["interval"]; ok {
+		f.IntervalMinutes = int(opt.IntValue())
+	}
+	if opt, ok := opts["max_items"]; ok {
+		f.MaxItems = int(opt.IntValue())
+	}
+
+	// Fetch once up front so a bad URL is reported now rather than in the logs.
+	// The fetch can outlast the interaction deadline, so the reply is deferred.
+	s.InteractionRespond(i.Interaction, &discordgo.InteractionResponse{
+		Type: discordgo.InteractionResponseDeferredChannelMessageWithSource,
+		Data: &discordgo.InteractionResponseData{Flags: discordgo.MessageFlagsEphemeral},
+	})
+	reply := func(content string) {
+		s.InteractionResponseEdit(i.Interaction, &discordgo.WebhookEdit{Content: &content})
+	}
+	st := &feedState{}
+	items, err := fetchFeed(f.URL, st)
+	if err != nil {
+		reply("Couldn't read that feed: " + err.Error())
+		return
+	}
+	// The entries already in the feed count as seen, so only later ones are posted
+	st.LastPolled = time.Now()
+	st.Seen = map[string]time.Time{}
+	for _, item := range items {
+		if item.Key != "" {
+			st.Seen[item.Key] = st.LastPolled
+		}
+	}
+
+	_, err = updateNotificationChannel(nc.Name, func(c *NotificationChannel) {
+		if !slices.ContainsFunc(c.Feeds, func(existing NotificationFeed) bool { return existing.URL == f.URL }) {
+			c.Feeds = append(c.Feeds, f)
+		}
+	})
+	if err != nil {
+		reply("Failed to save notification channels: " + err.Error())
+		return
+	}
+	rememberFeedState(&nc, f, st)
+	reply(fmt.Sprintf("Feed added to %s with %d existing entries. Only entries published from now on will be posted, checked every %d minute(s).",
+		nc.Name, len(items), int(f.interval().Minutes())))
+}
+
+// Handle /delfeed: detach a feed from a notification channel
+func handleDelFeedCommand(s *discordgo.Session, i *discordgo.InteractionCreate) {
+	if !isAdministrator(i) {
+		respondEphemeral(s, i, "You do not have permission to use this command.")
+		return
+	}
+	opts := optionMap(i.ApplicationCommandData().Options)
+	nc, ok := findNotificationChannel(opts["channel"].StringValue())
+	if !ok {
+		respondEphemeral(s, i, "Notification channel not found. Pick one from the suggestions.")
+		return
+	}
+	url := strings.TrimSpace(opts["url"].StringValue())
+	for _, f := range nc.Feeds {
+		if f.URL != url {
+			continue
+		}
+		_, err := updateNotificationChannel(nc.Name, func(c *NotificationChannel) {
+			c.Feeds = slices.DeleteFunc(c.Feeds, func(existing NotificationFeed) bool { return existing.URL == url })
+		})
+		if err != nil {
+			respondEphemeral(s, i, "Failed to save notification channels: "+err.Error())
+			return
+		}
+		respondEphemeral(s, i, "Feed removed from "+nc.Name+".")
+		forgetFeedState(&nc, f)
+		return
+	}
+	var urls []string
+	for _, f := range nc.Feeds {
+		urls = append(urls, f.URL)
+	}
+	if len(urls) == 0 {
+		respondEphemeral(s, i, nc.Name+" has no feeds.")
+		return
+	}
+	respondEphemeral(s, i, "That feed isn't attached to "+nc.Name+". Its feeds are:\n"+strings.Join(urls, "\n"))
+}
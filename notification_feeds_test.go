@@ -0,0 +1,281 @@
+package main
+
+import (
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+)
+
+const testRSSFeed = `<?xml version="1.0"?>
+<rss version="2.0"><channel>
+	<title>Example</title>
+	<item>
+		<title>Second post</title>
+		<link>https://example.com/2</link>
+		<guid>post-2</guid>
+		<pubDate>Tue, 02 Jan 2024 10:00:00 +0000</pubDate>
+		<description>&lt;p&gt;Hello &lt;b&gt;again&lt;/b&gt;&lt;/p&gt;</description>
+	</item>
+	<item>
+		<title>First post</title>
+		<link>https://example.com/1</link>
+		<description>Hello</description>
+	</item>
+</channel></rss>`
+
+const testAtomFeed = `<?xml version="1.0" encoding="utf-8"?>
+<feed xmlns="http://www.w3.org/2005/Atom">
+	<title>Example</title>
+	<entry>
+		<title>Release 1.2</title>
+		<id>urn:release:1.2</id>
+		<link rel="self" href="https://example.com/self"/>
+		<link href="https://example.com/releases/1.2"/>
+		<updated>2024-01-02T10:00:00Z</updated>
+		<content>Bug fixes</content>
+	</entry>
+</feed>`
+
+// A local feed server whose entries can be changed between polls
+type testFeedServer struct {
+	*httptest.Server
+	mu       sync.Mutex
+	status   int
+	etag     string
+	titles   []string // Newest first
+	requests int
+}
+
+func newTestFeedServer(t *testing.T) *testFeedServer {
+	fs := &testFeedServer{status: http.StatusOK}
+	fs.Server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		fs.mu.Lock()
+		defer fs.mu.Unlock()
+		fs.requests++
+		if fs.status != http.StatusOK {
+			w.WriteHeader(fs.status)
+			return
+		}
+		if fs.etag != "" {
+			if r.Header.Get("If-None-Match") == fs.etag {
+				w.WriteHeader(http.StatusNotModified)
+				return
+			}
+			w.Header().Set("ETag", fs.etag)
+		}
+		var b strings.Builder
+		b.WriteString(`<rss version="2.0"><channel>`)
+		for _, title := range fs.titles {
+			fmt.Fprintf(&b, "<item><title>%s</title><guid>%s</guid></item>", title, title)
+		}
+		b.WriteString(`</channel></rss>`)
+		w.Write([]byte(b.String()))
+	}))
+	t.Cleanup(fs.Close)
+	return fs
+}
+
+func (fs *testFeedServer) set(titles ...string) {
+	fs.mu.Lock()
+	defer fs.mu.Unlock()
+	fs.titles = titles
+}
+
+// Poll through pollFeed, returning the titles it posted in order
+func pollTitles(t *testing.T, f NotificationFeed, st *feedState) []string {
+	t.Helper()
+	var posted []string
+	err := pollFeed(f, st, time.Now(), func(item feedItem) error {
+		posted = append(posted, item.Title)
+		return nil
+	})
+	if err != nil {
+		t.Fatalf("pollFeed: %v", err)
+	}
+	return posted
+}
+
+func TestParseFeedRSS(t *testing.T) {
+	items, err := parseFeed([]byte(testRSSFeed))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(items) != 2 {
+		t.Fatalf("got %d items, want 2", len(items))
+	}
+	first := items[0]
+	if first.Key != "post-2" || first.Title != "Second post" || first.Link != "https://example.com/2" {
+		t.Errorf("first item = %+v", first)
+	}
+	if first.Summary != "Hello again" {
+		t.Errorf("summary = %q, want markup stripped", first.Summary)
+	}
+	if want := time.Date(2024, 1, 2, 10, 0, 0, 0, time.UTC); !first.Published.Equal(want) {
+		t.Errorf("published = %v, want %v", first.Published, want)
+	}
+	// Without a guid the link identifies the entry
+	if items[1].Key != "https://example.com/1" {
+		t.Errorf("second item key = %q", items[1].Key)
+	}
+}
+
+func TestParseFeedAtom(t *testing.T) {
+	items, err := parseFeed([]byte(testAtomFeed))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(items) != 1 {
+		t.Fatalf("got %d items, want 1", len(items))
+	}
+	item := items[0]
+	if item.Key != "urn:release:1.2" || item.Link != "https://example.com/releases/1.2" || item.Summary != "Bug fixes" {
+		t.Errorf("item = %+v", item)
+	}
+	if item.Published.IsZero() {
+		t.Error("published time wasn't read from <updated>")
+	}
+}
+
+func TestParseFeedRejectsOtherDocuments(t *testing.T) {
+	if _, err := parseFeed([]byte(`<html><body>not a feed</body></html>`)); err == nil {
+		t.Error("expected an error for an HTML page")
+	}
+	if _, err := parseFeed([]byte(`not xml`)); err == nil {
+		t.Error("expected an error for plain text")
+	}
+}
+
+func TestFetchFeedNotModified(t *testing.T) {
+	fs := newTestFeedServer(t)
+	fs.etag = `"v1"`
+	fs.set("a")
+
+	st := &feedState{}
+	items, err := fetchFeed(fs.URL, st)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(items) != 1 || st.ETag != `"v1"` {
+		t.Fatalf("first fetch: %d items, etag %q", len(items), st.ETag)
+	}
+	items, err = fetchFeed(fs.URL, st)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if items != nil {
+		t.Errorf("a 304 should return nil items, got %v", items)
+	}
+}
+
+func TestFetchFeedErrors(t *testing.T) {
+	fs := newTestFeedServer(t)
+	fs.status = http.StatusInternalServerError
+	if _, err := fetchFeed(fs.URL, &feedState{}); err == nil {
+		t.Error("expected an error for a 500 response")
+	}
+
+	big := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte("<rss><channel>"))
+		w.Write(make([]byte, maxFeedSize))
+	}))
+	defer big.Close()
+	_, err := fetchFeed(big.URL, &feedState{})
+	if err == nil || !strings.Contains(err.Error(), "larger than") {
+		t.Errorf("expected a size error, got %v", err)
+	}
+}
+
+func TestPollFeedPostsOnlyNewEntries(t *testing.T) {
+	fs := newTestFeedServer(t)
+	fs.set("b", "a")
+	f := NotificationFeed{URL: fs.URL}
+	st := &feedState{}
+
+	if posted := pollTitles(t, f, st); len(posted) != 0 {
+		t.Fatalf("first poll posted %v; it should only record existing entries", posted)
+	}
+	fs.set("d", "c", "b", "a")
+	if posted := pollTitles(t, f, st); strings.Join(posted, ",") != "c,d" {
+		t.Errorf("second poll posted %v, want [c d] oldest first", posted)
+	}
+	if posted := pollTitles(t, f, st); len(posted) != 0 {
+		t.Errorf("third poll posted %v again", posted)
+	}
+}
+
+func TestPollFeedFailedFirstFetchDoesNotFlood(t *testing.T) {
+	fs := newTestFeedServer(t)
+	fs.status = http.StatusBadGateway
+	f := NotificationFeed{URL: fs.URL}
+	st := &feedState{}
+
+	if err := pollFeed(f, st, time.Now(), func(feedItem) error { return nil }); err == nil {
+		t.Fatal("expected the failed fetch to be reported")
+	}
+	fs.mu.Lock()
+	fs.status = http.StatusOK
+	fs.mu.Unlock()
+	fs.set("c", "b", "a")
+	if posted := pollTitles(t, f, st); len(posted) != 0 {
+		t.Errorf("the first successful poll posted %v", posted)
+	}
+}
+
+func TestPollFeedFilters(t *testing.T) {
+	fs := newTestFeedServer(t)
+	f := NotificationFeed{URL: fs.URL, Include: []string{"release"}, Exclude: []string{"beta"}}
+	st := &feedState{}
+	pollTitles(t, f, st)
+
+	fs.set("release 2.0", "release 2.0 beta", "blog post")
+	if posted := pollTitles(t, f, st); strings.Join(posted, ",") != "release 2.0" {
+		t.Errorf("posted %v, want only [release 2.0]", posted)
+	}
+	// Filtered entries count as seen, so they stay skipped
+	if posted := pollTitles(t, NotificationFeed{URL: fs.URL}, st); len(posted) != 0 {
+		t.Errorf("posted filtered entries %v later", posted)
+	}
+}
+
+func TestPollFeedMaxItems(t *testing.T) {
+	fs := newTestFeedServer(t)
+	f := NotificationFeed{URL: fs.URL, MaxItems: 2}
+	st := &feedState{}
+	pollTitles(t, f, st)
+
+	fs.set("e", "d", "c", "b", "a")
+	if posted := pollTitles(t, f, st); strings.Join(posted, ",") != "a,b" {
+		t.Errorf("first batch %v, want [a b]", posted)
+	}
+	if posted := pollTitles(t, f, st); strings.Join(posted, ",") != "c,d" {
+		t.Errorf("second batch %v, want [c d]", posted)
+	}
+	if posted := pollTitles(t, f, st); strings.Join(posted, ",") != "e" {
+		t.Errorf("third batch %v, want [e]", posted)
+	}
+}
+
+func TestPollFeedRetriesAfterFailedPost(t *testing.T) {
+	fs := newTestFeedServer(t)
+	fs.etag = `"v1"`
+	f := NotificationFeed{URL: fs.URL}
+	st := &feedState{}
+	pollTitles(t, f, st)
+
+	fs.mu.Lock()
+	fs.etag = `"v2"`
+	fs.mu.Unlock()
+	fs.set("a")
+	err := pollFeed(f, st, time.Now(), func(feedItem) error { return fmt.Errorf("discord is down") })
+	if err == nil {
+		t.Fatal("expected the failed post to be reported")
+	}
+	// The feed is unchanged, but the entry still has to go out
+	if posted := pollTitles(t, f, st); strings.Join(posted, ",") != "a" {
+		t.Errorf("retry posted %v, want [a]", posted)
+	}
+}
@@ -0,0 +1,246 @@
+package main
+
+import (
+	"fmt"
+	"log"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/bwmarrin/discordgo"
+)
+
+// --- Notification Preferences Section ---
+
+// Component IDs for the "Manage my notifications" menu. They deliberately
+// avoid the notification_ prefix used by the per-channel buttons.
+const (
+	notificationPrefsOpenID    = "notifprefs_open"
+	notificationPrefsWithID    = "notifprefs_with"
+	notificationPrefsWithoutID = "notifprefs_without"
+	notificationPrefsSaveID    = "notifprefs_save"
+
+	// Discord select menus hold at most 25 options
+	notificationPrefsMaxOptions = 25
+	notificationPrefsTTL        = 15 * time.Minute
+)
+
+// Selections a member has made in their preferences menu but not saved yet
+type pendingNotificationPrefs struct {
+	With    map[string]bool
+	Without map[string]bool
+	Expires time.Time
+}
+
+var (
+	pendingNotificationPrefsByUser = map[string]*pendingNotificationPrefs{}
+	pendingNotificationPrefsMu     sync.Mutex
+)
+
+// Channels offered in the preferences menu, and how many channels there are in total
+func notificationPrefsChannels() ([]NotificationChannel, int) {
+	channels := sortedNotificationChannels()
+	if len(channels) > notificationPrefsMaxOptions {
+		return channels[:notificationPrefsMaxOptions], len(channels)
+	}
+	return channels, len(channels)
+}
+
+// Build one multi-select pre-filled with the selected channel names
+func notificationPrefsSelect(customID, placeholder string, channels []NotificationChannel, selected map[string]bool) discordgo.SelectMenu {
+	var options []discordgo.SelectMenuOption
+	for _, nc := range channels {
+		options = append(options, discordgo.SelectMenuOption{
+			Label:   nc.Name,
+			Value:   nc.Name,
+			Default: selected[nc.Name],
+		})
+	}
+	return discordgo.SelectMenu{
+		MenuType:    discordgo.StringSelectMenu,
+		CustomID:    customID,
+		Placeholder: placeholder,
+		MinValues:   intPtr(0),
+		MaxValues:   len(options),
+		Options:     options,
+	}
+}
+
+// Handle the "Manage my notifications" button on the panel
+func handleNotificationPrefsOpen(s *discordgo.Session, i *discordgo.InteractionCreate) {
+	channels, total := notificationPrefsChannels()
+	if len(channels) == 0 {
+		respondEphemeral(s, i, "No notification channels are configured yet.")
+		return
+	}
+
+	prefs := &pendingNotificationPrefs{
+		With:    map[string]bool{},
+		Without: map[string]bool{},
+		Expires: time.Now().Add(notificationPrefsTTL),
+	}
+	for idx := range channels {
+		switch subscriptionState(i.Member.Roles, &channels[idx]) {
+		case subscribedWithNotifications:
+			prefs.With[channels[idx].Name] = true
+		case subscribedWithoutNotifications:
+			prefs.Without[channels[idx].Name] = true
+		}
+	}
+	pendingNotificationPrefsMu.Lock()
+	for key, pending := range pendingNotificationPrefsByUser {
+		if time.Now().After(pending.Expires) {
+			delete(pendingNotificationPrefsByUser, key)
+		}
+	}
+	pendingNotificationPrefsByUser[i.Member.User.ID] = prefs
+	pendingNotificationPrefsMu.Unlock()
+
+	description := "Pick the channels you want in each list, then press **Save**. " +
+		"Channels left out of both lists are unsubscribed. A channel picked in both lists gets notifications."
+	if total > len(channels) {
+		description += fmt.Sprintf("\n\nOnly the first %d channels fit in this menu; use the panel buttons for the rest.", len(channels))
+	}
+	s.InteractionRespond(i.Interaction, &discordgo.InteractionResponse{
+		Type: discordgo.InteractionResponseChannelMessageWithSource,
+		Data: &discordgo.InteractionResponseData{
+			Embeds: []*discordgo.MessageEmbed{{
+				Title:       "Manage My Notifications",
+				Description: description,
+			}},
+			Flags: discordgo.MessageFlagsEphemeral,
+			Components: []discordgo.MessageComponent{
+				discordgo.ActionsRow{Components: []discordgo.MessageComponent{
+					notificationPrefsSelect(notificationPrefsWithID, "🔔 Subscribed with notifications", channels, prefs.With),
+				}},
+				discordgo.ActionsRow{Components: []discordgo.MessageComponent{
+					notificationPrefsSelect(notificationPrefsWithoutID, "🔕 Subscribed without notifications", channels, prefs.Without),
+				}},
+				discordgo.ActionsRow{Components: []discordgo.MessageComponent{
+					discordgo.Button{Label: "Save", CustomID: notificationPrefsSaveID, Style: discordgo.SuccessButton},
+				}},
+			},
+		},
+	})
+}
+
+// Handle a change to either multi-select; selections are kept until Save
+func handleNotificationPrefsSelect(s *discordgo.Session, i *discordgo.InteractionCreate) {
+	data := i.MessageComponentData()
+	selected := map[string]bool{}
+	for _, name := range data.Values {
+		selected[name] = true
+	}
+
+	pendingNotificationPrefsMu.Lock()
+	prefs, ok := pendingNotificationPrefsByUser[i.Member.User.ID]
+	if ok {
+		if data.CustomID == notificationPrefsWithID {
+			prefs.With = selected
+		} else {
+			prefs.Without = selected
+		}
+		prefs.Expires = time.Now().Add(notificationPrefsTTL)
+	}
+	pendingNotificationPrefsMu.Unlock()
+
+	if !ok {
+		respondEphemeral(s, i, "This menu has expired. Press **Manage my notifications** again.")
+		return
+	}
+	s.InteractionRespond(i.Interaction, &discordgo.InteractionResponse{
+		Type: discordgo.InteractionResponseDeferredMessageUpdate,
+	})
+}
+
+// Handle Save: work out the member's new role list and apply it in one edit
+func handleNotificationPrefsSave(s *discordgo.Session, i *discordgo.InteractionCreate) {
+	pendingNotificationPrefsMu.Lock()
+	prefs, ok := pendingNotificationPrefsByUser[i.Member.User.ID]
+	delete(pendingNotificationPrefsByUser, i.Member.User.ID)
+	pendingNotificationPrefsMu.Unlock()
+
+	update := func(content string) {
+		s.InteractionRespond(i.Interaction, &discordgo.InteractionResponse{
+			Type: discordgo.InteractionResponseUpdateMessage,
+			Data: &discordgo.InteractionResponseData{
+				Content:    content,
+				Embeds:     []*discordgo.MessageEmbed{},
+				Components: []discordgo.MessageComponent{},
+			},
+		})
+	}
+	if !ok || time.Now().After(prefs.Expires) {
+		update("This menu has expired. Press **Manage my notifications** again.")
+		return
+	}
+
+	// Drop every role the menu manages, then add back what was selected
+	channels, _ := notificationPrefsChannels()
+	managed := map[string]bool{}
+	wanted := map[string]bool{}
+	var changes, left []string
+	for idx := range channels {
+		nc := &channels[idx]
+		managed[nc.AccessRoleID] = true
+		managed[nc.NotificationRoleID] = true
+
+		next := notSubscribed
+		switch {
+		case prefs.With[nc.Name]:
+			next = subscribedWithNotifications
+			wanted[nc.AccessRoleID] = true
+			wanted[nc.NotificationRoleID] = true
+		case prefs.Without[nc.Name]:
+			next = subscribedWithoutNotifications
+			wanted[nc.AccessRoleID] = true
+		}
+		if next != subscriptionState(i.Member.Roles, nc) {
+			changes = append(changes, describeSubscriptionState(next, nc))
+			if next == notSubscribed {
+				left = append(left, nc.Name)
+			}
+		}
+	}
+	if len(changes) == 0 {
+		update("No changes to save; your subscriptions are already up to date.")
+		return
+	}
+
+	var roles []string
+	for _, roleID := range i.Member.Roles {
+		if !managed[roleID] {
+			roles = append(roles, roleID)
+		}
+	}
+	for roleID := range wanted {
+		roles = append(roles, roleID)
+	}
+	if roles == nil {
+		roles = []string{}
+	}
+
+	// A single member edit instead of one request per role; discordgo queues
+	// the request behind the guild member bucket and retries on 429s.
+	if _, err := s.GuildMemberEdit(config.GuildID, i.Member.User.ID, &discordgo.GuildMemberParams{Roles: &roles}); err != nil {
+		log.Printf("Notification preferences update error for %s: %v", i.Member.User.ID, err)
+		var roleIDs []string
+		for roleID := range managed {
+			roleIDs = append(roleIDs, roleID)
+		}
+		go explainRoleFailure(s, "notification preferences", roleIDs...)
+		update("Failed to update your roles. Please contact an admin.")
+		return
+	}
+	// Digest links wouldn't open for channels the member left
+	for _, name := range left {
+		if digestCadence(i.Member.User.ID, name) == "" {
+			continue
+		}
+		if err := setDigestCadence(i.Member.User.ID, name, "", time.Now()); err != nil {
+			log.Printf("Error saving digest subscriptions: %v", err)
+		}
+		changes = append(changes, fmt.Sprintf("📭 You'll no longer get digests of %s.", name))
+	}
+	update("✅ Your notification preferences have been saved.\n\n" + strings.Join(changes, "\n"))
+}
@@ -0,0 +1,231 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"log"
+	"strings"
+
+	"github.com/bwmarrin/discordgo"
+)
+
+// --- Notification Channel Provisioning Section ---
+
+// Create the access role, notification role and a private text channel for a
+// new notification channel. Anything already created is removed again if a
+// later step fails.
+func provisionNotificationChannel(s *discordgo.Session, name, categoryID string) (NotificationChannel, error) {
+	nc := NotificationChannel{Name: name, Provisioned: true}
+	noPermissions := int64(0)
+	notMentionable := false
+
+	accessRole, err := s.GuildRoleCreate(config.GuildID, &discordgo.RoleParams{
+		Name:        name,
+		Permissions: &noPermissions,
+		Mentionable: &notMentionable,
+	})
+	if err != nil {
+		return nc, fmt.Errorf("creating the access role: %w", err)
+	}
+	nc.AccessRoleID = accessRole.ID
+
+	notificationRole, err := s.GuildRoleCreate(config.GuildID, &discordgo.RoleParams{
+		Name:        name + " Notifications",
+		Permissions: &noPermissions,
+		Mentionable: &notMentionable,
+	})
+	if err != nil {
+		teardownNotificationChannel(s, nc)
+		return nc, fmt.Errorf("creating the notification role: %w", err)
+	}
+	nc.NotificationRoleID = notificationRole.ID
+
+	// Only the access role can see the channel. The bot gets an explicit
+	// overwrite so it can post and ping the notification role, which is
+	// deliberately not mentionable by members.
+	channel, err := s.GuildChannelCreateComplex(config.GuildID, discordgo.GuildChannelCreateData{
+		Name:     name,
+		Type:     discordgo.ChannelTypeGuildText,
+		ParentID: categoryID,
+		PermissionOverwrites: []*discordgo.PermissionOverwrite{
+			{
+				ID:   config.GuildID, // @everyone
+				Type: discordgo.PermissionOverwriteTypeRole,
+				Deny: discordgo.PermissionViewChannel,
+			},
+			{
+				ID:    accessRole.ID,
+				Type:  discordgo.PermissionOverwriteTypeRole,
+				Allow: discordgo.PermissionViewChannel | discordgo.PermissionReadMessageHistory,
+			},
+			{
+				ID:   s.State.User.ID,
+				Type: discordgo.PermissionOverwriteTypeMember,
+				Allow: discordgo.PermissionViewChannel | discordgo.PermissionSendMessages |
+					discordgo.PermissionEmbedLinks | discordgo.PermissionMentionEveryone,
+			},
+		},
+	})
+	if err != nil {
+		teardownNotificationChannel(s, nc)
+		return nc, fmt.Errorf("creating the channel: %w", err)
+	}
+	nc.ChannelID = channel.ID
+	return nc, nil
+}
+
+// Delete the channel and roles of a provisioned notification channel
+func teardownNotificationChannel(s *discordgo.Session, nc NotificationChannel) error {
+	var errs []error
+	if nc.ChannelID != "" {
+		if _, err := s.ChannelDelete(nc.ChannelID); err != nil {
+			errs = append(errs, fmt.Errorf("deleting the channel: %w", err))
+		}
+	}
+	for _, roleID := range []string{nc.NotificationRoleID, nc.AccessRoleID} {
+		if roleID == "" {
+			continue
+		}
+		if err := s.GuildRoleDelete(config.GuildID, roleID); err != nil {
+			errs = append(errs, fmt.Errorf("deleting role %s: %w", roleID, err))
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// Handle /addnotificationchannel: register existing IDs, or create everything with create:true
+func handleAddNotificationChannelCommand(s *discordgo.Session, i *discordgo.InteractionCreate) {
+	if !isAdministrator(i) {
+		respondEphemeral(s, i, "You do not have permission to use this command.")
+		return
+	}
+	opts := optionMap(i.ApplicationCommandData().Options)
+	name := strings.TrimSpace(opts["name"].StringValue())
+	for _, nc := range notificationChannelsSnapshot() {
+		if strings.EqualFold(nc.Name, name) {
+			respondEphemeral(s, i, "A notification channel with that name already exists.")
+			return
+		}
+	}
+	_, hasChannel := opts["channel"]
+	_, hasRole := opts["role"]
+	_, hasNotificationRole := opts["notificationrole"]
+	create := false
+	if opt, ok := opts["create"]; ok {
+		create = opt.BoolValue()
+	}
+
+	if !create && (!hasChannel || !hasRole || !hasNotificationRole) {
+		respondEphemeral(s, i, "Give channel, role and notificationrole, or set create:true to have the bot make them.")
+		return
+	}
+	if create && (hasChannel || hasRole || hasNotificationRole) {
+		respondEphemeral(s, i, "With create:true the bot makes the channel and roles itself; leave channel, role and notificationrole out.")
+		return
+	}
+
+	// Checking roles, creating resources and refreshing the panel can outlast
+	// the interaction deadline
+	s.InteractionRespond(i.Interaction, &discordgo.InteractionResponse{
+		Type: discordgo.InteractionResponseDeferredChannelMessageWithSource,
+		Data: &discordgo.InteractionResponseData{Flags: discordgo.MessageFlagsEphemeral},
+	})
+	reply := func(content string) {
+		s.InteractionResponseEdit(i.Interaction, &discordgo.WebhookEdit{Content: &content})
+	}
+
+	if !create {
+		nc := NotificationChannel{
+			Name:               name,
+			ChannelID:          parseID(opts["channel"].StringValue()),
+			AccessRoleID:       parseID(opts["role"].StringValue()),
+			NotificationRoleID: parseID(opts["notificationrole"].StringValue()),
+		}
+		if problems := preflightRoles(s, []roleCheck{
+			{"role", nc.AccessRoleID, true},
+			{"notificationrole", nc.NotificationRoleID, true},
+		}); len(problems) > 0 {
+			reply("The bot couldn't hand out these roles, so the channel wasn't added:\n- " + strings.Join(problems, "\n- "))
+			return
+		}
+		if err := addNotificationChannel(nc); err != nil {
+			reply("The notification channel wasn't added: " + err.Error())
+			return
+		}
+		reply("Notification channel added.")
+		updateNotificationsEmbed(s)
+		return
+	}
+
+	categoryID := ""
+	if opt, ok := opts["category"]; ok {
+		categoryID = opt.ChannelValue(nil).ID
+	}
+	nc, err := provisionNotificationChannel(s, name, categoryID)
+	if err != nil {
+		log.Printf("Error provisioning notification channel %s: %v", name, err)
+		reply("Couldn't set up the channel (" + err.Error() + "). Anything already created was removed again; check the bot has Manage Roles and Manage Channels.")
+		return
+	}
+	if err := addNotificationChannel(nc); err != nil {
+		teardownNotificationChannel(s, nc)
+		reply("The notification channel wasn't added, and the channel and roles were removed again: " + err.Error())
+		return
+	}
+	reply(fmt.Sprintf("Notification channel added. Created <#%s> with access role <@&%s> and notification role <@&%s>.",
+		nc.ChannelID, nc.AccessRoleID, nc.NotificationRoleID))
+	updateNotificationsEmbed(s)
+}
+
+// Handle /delnotificationchannel, deleting the channel and roles too with teardown:true
+func handleDelNotificationChannelCommand(s *discordgo.Session, i *discordgo.InteractionCreate) {
+	if !isAdministrator(i) {
+		respondEphemeral(s, i, "You do not have permission to use this command.")
+		return
+	}
+	opts := optionMap(i.ApplicationCommandData().Options)
+	name := opts["name"].StringValue()
+	teardown := false
+	if opt, ok := opts["teardown"]; ok {
+		teardown = opt.BoolValue()
+	}
+	var nc *NotificationChannel
+	channels := notificationChannelsSnapshot()
+	for idx := range channels {
+		if strings.EqualFold(channels[idx].Name, name) {
+			nc = &channels[idx]
+			break
+		}
+	}
+	if nc == nil {
+		respondEphemeral(s, i, "Notification channel not found.")
+		return
+	}
+	if teardown && !nc.Provisioned {
+		respondEphemeral(s, i, "teardown only applies to channels the bot created with create:true. Delete this one's channel and roles by hand, or run the command again without teardown.")
+		return
+	}
+
+	if _, err := removeNotificationChannel(nc.Name); err != nil {
+		respondEphemeral(s, i, "Failed to delete the notification channel: "+err.Error())
+		return
+	}
+	if !teardown {
+		respondEphemeral(s, i, "Notification channel deleted.")
+		updateNotificationsEmbed(s)
+		return
+	}
+
+	// Deleting three resources can outlast the interaction deadline
+	s.InteractionRespond(i.Interaction, &discordgo.InteractionResponse{
+		Type: discordgo.InteractionResponseDeferredChannelMessageWithSource,
+		Data: &discordgo.InteractionResponseData{Flags: discordgo.MessageFlagsEphemeral},
+	})
+	updateNotificationsEmbed(s)
+	content := "Notification channel deleted, along with its channel and roles."
+	if err := teardownNotificationChannel(s, *nc); err != nil {
+		log.Printf("Error tearing down notification channel %s: %v", nc.Name, err)
+		content = "Notification channel deleted, but some of its channel or roles couldn't be removed: " + err.Error()
+	}
+	s.InteractionResponseEdit(i.Interaction, &discordgo.WebhookEdit{Content: &content})
+}
@@ -0,0 +1,331 @@
+package main
+
+import (
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"log"
+	"net/http"
+	"net/url"
+	"os"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/bwmarrin/discordgo"
+)
+
+// --- Notification Webhooks Section ---
+
+// External systems post to /hooks/notify/<channel name> with:
+//
+//	X-Webhook-Timestamp: unix seconds
+//	X-Webhook-Signature: sha256=<hex HMAC-SHA256 of "<timestamp>.<body>" keyed by the channel's secret>
+//	Idempotency-Key:     optional; repeats within webhookIdempotencyTTL are not posted again
+const (
+	webhookPathPrefix     = "/hooks/notify/"
+	webhookAuditFile      = "webhook_audit.jsonl"
+	webhookIdemFile       = "webhook_idempotency.json"
+	webhookIdempotencyTTL = 24 * time.Hour
+	webhookMaxSkew        = 5 * time.Minute
+	maxWebhookBodySize    = 64 << 10
+)
+
+// JSON body accepted by the webhook endpoint
+type webhookPayload struct {
+	Title          string `json:"title"`
+	Body           string `json:"body"`
+	URL            string `json:"url,omitempty"`
+	ImageURL       string `json:"image_url,omitempty"`
+	Ping           bool   `json:"ping,omitempty"`
+	IdempotencyKey string `json:"idempotency_key,omitempty"`
+}
+
+// One line of the webhook audit trail
+type webhookAuditEntry struct {
+	Time           time.Time `json:"time"`
+	Channel        string    `json:"channel"`
+	RemoteAddr     string    `json:"remote_addr"`
+	Status         int       `json:"status"`
+	Result         string    `json:"result"`
+	IdempotencyKey string    `json:"idempotency_key,omitempty"`
+	Title          string    `json:"title,omitempty"`
+	MessageID      string    `json:"message_id,omitempty"`
+}
+
+var (
+	webhookAuditMu sync.Mutex
+
+	// Idempotency keys seen recently, keyed by channel name and key
+	webhookKeys   = map[string]time.Time{}
+	webhookKeysMu sync.Mutex
+)
+
+// Load recently used idempotency keys from file
+func loadWebhookKeys() error {
+	b, err := os.ReadFile(webhookIdemFile)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil
+		}
+		return err
+	}
+	return json.Unmarshal(b, &webhookKeys)
+}
+
+// Save idempotency keys to file. Callers must hold webhookKeysMu.
+func saveWebhookKeys() error {
+	b, err := json.MarshalIndent(webhookKeys, "", "  ")
+	if err != nil {
+		return err
+	}
+	return writeFileAtomic(webhookIdemFile, b, 0644)
+}
+
+// Claim an idempotency key, reporting false when it was already used
+func claimWebhookKey(key string, now time.Time) bool {
+	webhookKeysMu.Lock()
+	defer webhookKeysMu.Unlock()
+	for k, seen := range webhookKeys {
+		if now.Sub(seen) > webhookIdempotencyTTL {
+			delete(webhookKeys, k)
+		}
+	}
+	if _, ok := webhookKeys[key]; ok {
+		return false
+	}
+	webhookKeys[key] = now
+	if err := saveWebhookKeys(); err != nil {
+		log.Printf("Error saving webhook idempotency keys: %v", err)
+	}
+	return true
+}
+
+// Release a claimed key when the post failed so the sender can retry
+func releaseWebhookKey(key string) {
+	webhookKeysMu.Lock()
+	defer webhookKeysMu.Unlock()
+	delete(webhookKeys, key)
+	if err := saveWebhookKeys(); err != nil {
+		log.Printf("Error saving webhook idempotency keys: %v", err)
+	}
+}
+
+// Append an entry to the webhook audit trail
+func recordWebhookAudit(entry webhookAuditEntry) {
+	b, err := json.Marshal(entry)
+	if err != nil {
+		log.Printf("Error encoding webhook audit entry: %v", err)
+		return
+	}
+	webhookAuditMu.Lock()
+	defer webhookAuditMu.Unlock()
+	f, err := os.OpenFile(webhookAuditFile, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0644)
+	if err != nil {
+		log.Printf("Error opening webhook audit log: %v", err)
+		return
+	}
+	defer f.Close()
+	if _, err := f.Write(append(b, '\n')); err != nil {
+		log.Printf("Error writing webhook audit log: %v", err)
+	}
+}
+
+// Compute the expected signature for a webhook request
+func signWebhook(secret, timestamp string, body []byte) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write([]byte(timestamp + "."))
+	mac.Write(body)
+	return "sha256=" + hex.EncodeToString(mac.Sum(nil))
+}
+
+// Check a request's timestamp and signature against the channel secret
+func verifyWebhookSignature(secret string, r *http.Request, body []byte, now time.Time) string {
+	timestamp := r.Header.Get("X-Webhook-Timestamp")
+	unix, err := strconv.ParseInt(timestamp, 10, 64)
+	if err != nil {
+		return "missing or invalid X-Webhook-Timestamp"
+	}
+	if skew := now.Sub(time.Unix(unix, 0)); skew > webhookMaxSkew || skew < -webhookMaxSkew {
+		return "timestamp is outside the allowed window"
+	}
+	expected := signWebhook(secret, timestamp, body)
+	if !hmac.Equal([]byte(expected), []byte(r.Header.Get("X-Webhook-Signature"))) {
+		return "signature does not match"
+	}
+	return ""
+}
+
+// Build the embed for a webhook payload
+func webhookEmbed(p *webhookPayload) *discordgo.MessageEmbed {
+	embed := &discordgo.MessageEmbed{
+		Title:       p.Title,
+		Description: p.Body,
+		URL:         p.URL,
+		Timestamp:   time.Now().UTC().Format(time.RFC3339),
+	}
+	if p.ImageURL != "" {
+		embed.Image = &discordgo.MessageEmbedImage{URL: p.ImageURL}
+	}
+	return embed
+}
+
+// Validate a webhook payload, returning a problem description or ""
+func validateWebhookPayload(p *webhookPayload) string {
+	p.Title = strings.TrimSpace(p.Title)
+	p.Body = strings.TrimSpace(p.Body)
+	switch {
+	case p.Title == "" && p.Body == "":
+		return "title or body is required"
+	case len([]rune(p.Title)) > maxAnnouncementTitleLength:
+		return fmt.Sprintf("title must be at most %d characters", maxAnnouncementTitleLength)
+	case len([]rune(p.Body)) > maxEmbedDescriptionLength:
+		return fmt.Sprintf("body must be at most %d characters", maxEmbedDescriptionLength)
+	}
+	if p.URL != "" {
+		if problem := validateHTTPURL(p.URL); problem != "" {
+			return "url " + problem
+		}
+	}
+	if p.ImageURL != "" {
+		if problem := validateHTTPURL(p.ImageURL); problem != "" {
+			return "image_url " + problem
+		}
+	}
+	return ""
+}
+
+// HTTP handler that turns signed payloads into notification channel posts
+func notificationWebhookHandler(s *discordgo.Session) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		now := time.Now()
+		name, _ := url.PathUnescape(strings.TrimPrefix(r.URL.Path, webhookPathPrefix))
+		entry := webhookAuditEntry{Time: now.UTC(), Channel: name, RemoteAddr: r.RemoteAddr}
+		reply := func(status int, result string, body map[string]string) {
+			entry.Status, entry.Result = status, result
+			recordWebhookAudit(entry)
+			w.Header().Set("Content-Type", "application/json")
+			w.WriteHeader(status)
+			json.NewEncoder(w).Encode(body)
+		}
+		fail := func(status int, result, message string) {
+			reply(status, result, map[string]string{"error": message})
+		}
+
+		if r.Method != http.MethodPost {
+			fail(http.StatusMethodNotAllowed, "bad_method", "use POST")
+			return
+		}
+		nc, ok := findNotificationChannel(name)
+		if !ok || nc.WebhookSecret == "" {
+			fail(http.StatusNotFound, "unknown_channel", "no webhook is enabled for that channel")
+			return
+		}
+		body, err := io.ReadAll(io.LimitReader(r.Body, maxWebhookBodySize+1))
+		if err != nil || len(body) > maxWebhookBodySize {
+			fail(http.StatusRequestEntityTooLarge, "too_large", fmt.Sprintf("body must be at most %d KB", maxWebhookBodySize>>10))
+			return
+		}
+		if problem := verifyWebhookSignature(nc.WebhookSecret, r, body, now); problem != "" {
+			fail(http.StatusUnauthorized, "bad_signature", problem)
+			return
+		}
+
+		var payload webhookPayload
+		if err := json.Unmarshal(body, &payload); err != nil {
+			fail(http.StatusBadRequest, "bad_payload", "body must be a JSON object")
+			return
+		}
+		if key := r.Header.Get("Idempotency-Key"); key != "" {
+			payload.IdempotencyKey = key
+		}
+		entry.IdempotencyKey, entry.Title = payload.IdempotencyKey, payload.Title
+		if problem := validateWebhookPayload(&payload); problem != "" {
+			fail(http.StatusBadRequest, "bad_payload", problem)
+			return
+		}
+
+		key := ""
+		if payload.IdempotencyKey != "" {
+			key = nc.Name + "|" + payload.IdempotencyKey
+			if !claimWebhookKey(key, now) {
+				reply(http.StatusOK, "duplicate", map[string]string{"status": "duplicate"})
+				return
+			}
+		}
+		msg := &discordgo.MessageSend{
+			Embeds:          []*discordgo.MessageEmbed{webhookEmbed(&payload)},
+			AllowedMentions: &discordgo.MessageAllowedMentions{},
+		}
+		if payload.Ping && nc.NotificationRoleID != "" {
+			msg.Content = "<@&" + nc.NotificationRoleID + ">"
+			msg.AllowedMentions.Roles = []string{nc.NotificationRoleID}
+		}
+		sent, err := s.ChannelMessageSendComplex(nc.ChannelID, msg)
+		if err != nil {
+			if key != "" {
+				releaseWebhookKey(key)
+			}
+			log.Printf("Error posting webhook payload to %s: %v", nc.Name, err)
+			fail(http.StatusBadGateway, "discord_error", "could not post to Discord")
+			return
+		}
+		entry.MessageID = sent.ID
+		reply(http.StatusOK, "posted", map[string]string{"status": "posted", "message_id": sent.ID})
+	}
+}
+
+// Handle /webhooksecret: create or rotate a channel's webhook secret, or disable the webhook
+func handleWebhookSecretCommand(s *discordgo.Session, i *discordgo.InteractionCreate) {
+	if !isAdministrator(i) {
+		respondEphemeral(s, i, "You do not have permission to use this command.")
+		return
+	}
+	opts := optionMap(i.ApplicationCommandData().Options)
+	nc, ok := findNotificationChannel(opts["channel"].StringValue())
+	if !ok {
+		respondEphemeral(s, i, "Notification channel not found. Pick one from the suggestions.")
+		return
+	}
+	previous := nc.WebhookSecret
+	if opt, ok := opts["disable"]; ok && opt.BoolValue() {
+		if _, err := updateNotificationChannel(nc.Name, func(c *NotificationChannel) { c.WebhookSecret = "" }); err != nil {
+			respondEphemeral(s, i, "Failed to save notification channels: "+err.Error())
+			return
+		}
+		respondEphemeral(s, i, "Webhook disabled for "+nc.Name+". Requests signed with the old secret are now rejected.")
+		return
+	}
+	if httpListenAddr() == "" {
+		respondEphemeral(s, i, "The bot's HTTP server isn't enabled. Set httpListenAddr in config.json first.")
+		return
+	}
+
+	secretBytes := make([]byte, 32)
+	if _, err := rand.Read(secretBytes); err != nil {
+		respondEphemeral(s, i, "Failed to generate a secret: "+err.Error())
+		return
+	}
+	secret := hex.EncodeToString(secretBytes)
+	if _, err := updateNotificationChannel(nc.Name, func(c *NotificationChannel) { c.WebhookSecret = secret }); err != nil {
+		respondEphemeral(s, i, "Failed to save notification channels: "+err.Error())
+		return
+	}
+	endpoint := webhookPathPrefix + url.PathEscape(nc.Name)
+	if config.RedirectBaseURL != "" {
+		endpoint = strings.TrimRight(config.RedirectBaseURL, "/") + endpoint
+	}
+	action := "created"
+	if previous != "" {
+		action = "rotated; the old secret no longer works"
+	}
+	respondEphemeral(s, i, fmt.Sprintf("Webhook secret for %s %s.\n\n**Endpoint:** `POST %s`\n**Secret:** ||`%s`||\n\n"+
+		"Send `X-Webhook-Timestamp` (unix seconds) and `X-Webhook-Signature: sha256=<hex HMAC-SHA256 of \"<timestamp>.<body>\">`. "+
+		"Body: `{\"title\", \"body\", \"url\", \"image_url\", \"ping\"}`. Add an `Idempotency-Key` header to make retries safe.",
+		nc.Name, action, endpoint, secret))
+}
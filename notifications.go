@@ -2,7 +2,14 @@ package main
 
 import (
 	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
 	"os"
+	"slices"
+	"strings"
+	"sync"
+	"time"
 
 	"github.com/bwmarrin/discordgo"
 )
@@ -14,12 +21,27 @@ type NotificationChannel struct {
 	ChannelID          string `json:"channel_id"`
 	AccessRoleID       string `json:"access_role_id"`
 	NotificationRoleID string `json:"notification_role_id"`
+	Description        string `json:"description,omitempty"`
+	Emoji              string `json:"emoji,omitempty"`
+	Order              int    `json:"order,omitempty"` // Lower comes first on the panel; ties sort by name
+
+	Feeds         []NotificationFeed `json:"feeds,omitempty"`
+	WebhookSecret string             `json:"webhook_secret,omitempty"`
+	Provisioned   bool               `json:"provisioned,omitempty"` // Channel and roles were created by the bot
 }
 
-var notificationChannels []NotificationChannel
+// Guards notificationChannels. Commands, the HTTP server and background jobs
+// use it concurrently, so the helpers below hand out copies rather than
+// pointers into the slice, which removals shift.
+var (
+	notificationChannels   []NotificationChannel
+	notificationChannelsMu sync.Mutex
+)
 const notificationChannelsFile = "notification_channels.json"
 const notificationsChannelID = "1366043181925273731"
 
+var errNotificationChannelNotFound = errors.New("notification channel not found")
+
 // Load notification channels from file
 func loadNotificationChannels() error {
 	b, err := os.ReadFile(notificationChannelsFile)
@@ -33,7 +55,7 @@ func loadNotificationChannels() error {
 	return json.Unmarshal(b, &notificationChannels)
 }
 
-// Save notification channels to file
+// Save notification channels to file. Callers must hold notificationChannelsMu.
 func saveNotificationChannels() error {
 	b, err := json.MarshalIndent(notificationChannels, "", "  ")
 	if err != nil {
@@ -42,26 +64,349 @@ func saveNotificationChannels() error {
 	return os.WriteFile(notificationChannelsFile, b, 0644)
 }
 
-// Send the notifications embed with buttons
-func sendNotificationsEmbed(s *discordgo.Session) {
-	embed := &discordgo.MessageEmbed{
-		Title:       "Notifications",
-		Description: "Use the reaction buttons below to gain access to our notification channels.",
+// Copy a notification channel, including its feed list, so the copy can't be
+// changed through the shared slice
+func (nc NotificationChannel) clone() NotificationChannel {
+	nc.Feeds = slices.Clone(nc.Feeds)
+	return nc
+}
+
+// Copies of every notification channel
+func notificationChannelsSnapshot() []NotificationChannel {
+	notificationChannelsMu.Lock()
+	defer notificationChannelsMu.Unlock()
+	channels := make([]NotificationChannel, 0, len(notificationChannels))
+	for _, nc := range notificationChannels {
+		channels = append(channels, nc.clone())
+	}
+	return channels
+}
+
+// Add a notification channel and save, refusing a name that's already taken
+func addNotificationChannel(nc NotificationChannel) error {
+	notificationChannelsMu.Lock()
+	defer notificationChannelsMu.Unlock()
+	for _, existing := range notificationChannels {
+		if strings.EqualFold(existing.Name, nc.Name) {
+			return fmt.Errorf("a notification channel named %s already exists", existing.Name)
+		}
+	}
+	notificationChannels = append(notificationChannels, nc)
+	if err := saveNotificationChannels(); err != nil {
+		notificationChannels = notificationChannels[:len(notificationChannels)-1]
+		return err
+	}
+	return nil
+}
+
+// Change a notification channel and save, undoing the change if the save fails.
+// change runs with notificationChannelsMu held, so it must not block.
+func updateNotificationChannel(name string, change func(nc *NotificationChannel)) (NotificationChannel, error) {
+	notificationChannelsMu.Lock()
+	defer notificationChannelsMu.Unlock()
+	for idx := range notificationChannels {
+		if notificationChannels[idx].Name != name {
+			continue
+		}
+		previous := notificationChannels[idx]
+		updated := previous.clone()
+		change(&updated)
+		notificationChannels[idx] = updated
+		if err := saveNotificationChannels(); err != nil {
+			notificationChannels[idx] = previous
+			return previous.clone(), err
+		}
+		return updated.clone(), nil
+	}
+	return NotificationChannel{}, errNotificationChannelNotFound
+}
+
+// Remove a notification channel by name, ignoring case, and save.
+// Returns the removed channel.
+func removeNotificationChannel(name string) (NotificationChannel, error) {
+	notificationChannelsMu.Lock()
+	defer notificationChannelsMu.Unlock()
+	for idx, nc := range notificationChannels {
+		if !strings.EqualFold(nc.Name, name) {
+			continue
+		}
+		previous := notificationChannels
+		notificationChannels = slices.Delete(slices.Clone(notificationChannels), idx, idx+1)
+		if err := saveNotificationChannels(); err != nil {
+			notificationChannels = previous
+			return nc, err
+		}
+		return nc, nil
+	}
+	return NotificationChannel{}, errNotificationChannelNotFound
+}
+
+// Serialises panel reconciliation
+var notificationsPanelMu sync.Mutex
+
+// Discord allows 5 buttons per row and 5 rows per message
+const (
+	buttonsPerRow     = 5
+	buttonsPerMessage = 25
+
+	notificationsPanelTitle       = "Notifications"
+	notificationsPanelPlaceholder = "No notification channels configured yet."
+)
+
+// Helper: Split buttons into action rows of at most five
+func buttonRows(buttons []discordgo.MessageComponent) []discordgo.MessageComponent {
+	var rows []discordgo.MessageComponent
+	for start := 0; start < len(buttons); start += buttonsPerRow {
+		end := min(start+buttonsPerRow, len(buttons))
+		rows = append(rows, discordgo.ActionsRow{Components: buttons[start:end]})
 	}
+	return rows
+}
+
+// Build the notifications panel pages, 25 buttons per message. The first page
+// gives up its last row to the "Manage my notifications" button.
+func notificationsPanelPages() []*discordgo.MessageSend {
+	channels := sortedNotificationChannels()
 	var buttons []discordgo.MessageComponent
-	for _, nc := range notificationChannels {
-		buttons = append(buttons, discordgo.Button{
+	for _, nc := range channels {
+		button := discordgo.Button{
 			Label:    nc.Name,
 			CustomID: "notification_" + nc.Name,
 			Style:    discordgo.PrimaryButton,
+		}
+		if nc.Emoji != "" {
+			name, id, animated := parseEmoji(nc.Emoji)
+			button.Emoji = &discordgo.ComponentEmoji{Name: name, ID: id, Animated: animated}
+		}
+		buttons = append(buttons, button)
+	}
+	var pages []*discordgo.MessageSend
+	for start := 0; start < len(buttons); {
+		perPage := buttonsPerMessage
+		if start == 0 {
+			perPage -= buttonsPerRow
+		}
+		end := min(start+perPage, len(buttons))
+		embed := &discordgo.MessageEmbed{
+			Title:       notificationsPanelTitle,
+			Description: "Use the reaction buttons below to gain access to our notification channels.",
+		}
+		components := buttonRows(buttons[start:end])
+		if start == 0 {
+			components = append(components, discordgo.ActionsRow{
+				Components: []discordgo.MessageComponent{
+					discordgo.Button{
+						Label:    "Manage my notifications",
+						CustomID: notificationPrefsOpenID,
+						Style:    discordgo.SecondaryButton,
+						Emoji:    &discordgo.ComponentEmoji{Name: "⚙️"},
+					},
+				},
+			})
+		} else {
+			embed.Title = notificationsPanelTitle + " (continued)"
+			embed.Description = "More notification channels."
+		}
+		embed.Description = truncateRunes(embed.Description+"\n\n"+notificationsDirectory(channels[start:end]), maxEmbedDescriptionLength)
+		pages = append(pages, &discordgo.MessageSend{
+			Embeds:     []*discordgo.MessageEmbed{embed},
+			Components: components,
 		})
+		start = end
+	}
+	return pages
+}
+
+// Reconcile the notifications panel in place: edit existing panel messages,
+// post or delete pages as the channel count changes, and clear the placeholder.
+func updateNotificationsEmbed(s *discordgo.Session) {
+	// Commands and the periodic refresh can overlap; reconcile one at a time
+	notificationsPanelMu.Lock()
+	defer notificationsPanelMu.Unlock()
+
+	botUser, err := s.User("@me")
+	if err != nil {
+		log.Printf("Could not get bot user: %v", err)
+		return
 	}
-	if len(buttons) == 0 {
-		s.ChannelMessageSend(notificationsChannelID, "No notification channels configured yet.")
+	messages, err := s.ChannelMessages(notificationsChannelID, 50, "", "", "")
+	if err != nil {
+		log.Printf("Error fetching messages for notifications update: %v", err)
 		return
 	}
-	s.ChannelMessageSendComplex(notificationsChannelID, &discordgo.MessageSend{
-		Embed:      embed,
-		Components: []discordgo.MessageComponent{discordgo.ActionsRow{Components: buttons}},
+
+	// Messages come newest first; panels are kept oldest first so page order is stable
+	var panels, placeholders []*discordgo.Message
+	for idx := len(messages) - 1; idx >= 0; idx-- {
+		msg := messages[idx]
+		if msg.Author == nil || msg.Author.ID != botUser.ID {
+			continue
+		}
+		switch {
+		case msg.Content == notificationsPanelPlaceholder:
+			placeholders = append(placeholders, msg)
+		case len(msg.Embeds) > 0 && strings.HasPrefix(msg.Embeds[0].Title, notificationsPanelTitle):
+			panels = append(panels, msg)
+		}
+	}
+
+	pages := notificationsPanelPages()
+	if len(pages) == 0 {
+		for _, msg := range panels {
+			_ = s.ChannelMessageDelete(notificationsChannelID, msg.ID)
+		}
+		if len(placeholders) == 0 {
+			s.ChannelMessageSend(notificationsChannelID, notificationsPanelPlaceholder)
+		}
+		return
+	}
+	for _, msg := range placeholders {
+		_ = s.ChannelMessageDelete(notificationsChannelID, msg.ID)
+	}
+	for idx, page := range pages {
+		if idx < len(panels) {
+			components := page.Components
+			_, err = s.ChannelMessageEditComplex(&discordgo.MessageEdit{
+				ID:         panels[idx].ID,
+				Channel:    notificationsChannelID,
+				Embeds:     &page.Embeds,
+				Components: &components,
+			})
+			if err != nil {
+				log.Printf("Error editing notifications embed: %v", err)
+			}
+			continue
+		}
+		if _, err := s.ChannelMessageSendComplex(notificationsChannelID, page); err != nil {
+			log.Printf("Error sending notifications embed: %v", err)
+		}
+	}
+	for _, msg := range panels[min(len(pages), len(panels)):] {
+		_ = s.ChannelMessageDelete(notificationsChannelID, msg.ID)
+	}
+}
+
+// Member subscription states for a notification channel
+const (
+	notSubscribed                  = "none"
+	subscribedWithoutNotifications = "without"
+	subscribedWithNotifications    = "with"
+)
+
+// Find a notification channel by its exact name, returning a copy
+func findNotificationChannel(name string) (NotificationChannel, bool) {
+	notificationChannelsMu.Lock()
+	defer notificationChannelsMu.Unlock()
+	for _, nc := range notificationChannels {
+		if nc.Name == name {
+			return nc.clone(), true
+		}
+	}
+	return NotificationChannel{}, false
+}
+
+// Work out a member's subscription state from their roles
+func subscriptionState(roles []string, nc *NotificationChannel) string {
+	switch {
+	case hasRole(roles, nc.AccessRoleID) && hasRole(roles, nc.NotificationRoleID):
+		return subscribedWithNotifications
+	case hasRole(roles, nc.AccessRoleID):
+		return subscribedWithoutNotifications
+	default:
+		return notSubscribed
+	}
+}
+
+// Describe a subscription state to the member
+func describeSubscriptionState(state string, nc *NotificationChannel) string {
+	switch state {
+	case subscribedWithNotifications:
+		return fmt.Sprintf("🔔 You're currently subscribed to %s with notifications.", nc.Name)
+	case subscribedWithoutNotifications:
+		return fmt.Sprintf("🔕 You're currently subscribed to %s without notifications.", nc.Name)
+	default:
+		return fmt.Sprintf("You're not subscribed to %s.", nc.Name)
+	}
+}
+
+// Remove a member's access and notification roles for a channel
+func unsubscribeMember(s *discordgo.Session, userID string, roles []string, nc *NotificationChannel) error {
+	for _, roleID := range []string{nc.NotificationRoleID, nc.AccessRoleID} {
+		if !hasRole(roles, roleID) {
+			continue
+		}
+		if err := s.GuildMemberRoleRemove(config.GuildID, userID, roleID); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Handle the "Unsubscribe" button in the subscription menu
+func handleNotificationUnsubscribeButton(s *discordgo.Session, i *discordgo.InteractionCreate) {
+	nc, ok := findNotificationChannel(strings.TrimPrefix(i.MessageComponentData().CustomID, "notification_unsub_"))
+	if !ok {
+		respondEphemeral(s, i, "Notification channel not found.")
+		return
+	}
+	if err := unsubscribeMember(s, i.Member.User.ID, i.Member.Roles, &nc); err != nil {
+		log.Printf("Role removal error: %v", err)
+		go explainRoleFailure(s, nc.Name+" unsubscribe", nc.AccessRoleID, nc.NotificationRoleID)
+		respondEphemeral(s, i, "Failed to remove roles. Please contact an admin.")
+		return
+	}
+	description := fmt.Sprintf("🚪 You have unsubscribed from %s.", nc.Name)
+	// The digest links wouldn't open without the access role
+	if digestCadence(i.Member.User.ID, nc.Name) != "" {
+		if err := setDigestCadence(i.Member.User.ID, nc.Name, "", time.Now()); err != nil {
+			log.Printf("Error saving digest subscriptions: %v", err)
+		}
+		description = fmt.Sprintf("🚪 You have unsubscribed from %s and its digest.", nc.Name)
+	}
+	s.InteractionRespond(i.Interaction, &discordgo.InteractionResponse{
+		Type: discordgo.InteractionResponseChannelMessageWithSource,
+		Data: &discordgo.InteractionResponseData{
+			Embeds: []*discordgo.MessageEmbed{{
+				Title:       "Subscription Updated",
+				Description: description,
+			}},
+			Flags: discordgo.MessageFlagsEphemeral,
+		},
 	})
 }
+
+// Handle /unsubscribeall: remove every notification channel role and digest the member has
+func handleUnsubscribeAllCommand(s *discordgo.Session, i *discordgo.InteractionCreate) {
+	var left, failed []string
+	channels := notificationChannelsSnapshot()
+	for idx := range channels {
+		nc := &channels[idx]
+		if subscriptionState(i.Member.Roles, nc) == notSubscribed && !hasRole(i.Member.Roles, nc.NotificationRoleID) {
+			continue
+		}
+		if err := unsubscribeMember(s, i.Member.User.ID, i.Member.Roles, nc); err != nil {
+			log.Printf("Role removal error: %v", err)
+			failed = append(failed, nc.Name)
+			continue
+		}
+		left = append(left, nc.Name)
+	}
+	digests, err := clearDigests(i.Member.User.ID)
+	if err != nil {
+		log.Printf("Error saving digest subscriptions: %v", err)
+	}
+	for _, name := range digests {
+		if !slices.Contains(left, name) && !slices.Contains(failed, name) {
+			left = append(left, name)
+		}
+	}
+	switch {
+	case len(left) == 0 && len(failed) == 0:
+		respondEphemeral(s, i, "You aren't subscribed to any notification channels.")
+	case len(failed) > 0:
+		respondEphemeral(s, i, fmt.Sprintf("Unsubscribed from %d channel(s), but couldn't remove roles for: %s. Please contact an admin.",
+			len(left), strings.Join(failed, ", ")))
+	default:
+		respondEphemeral(s, i, "🚪 You have unsubscribed from: "+strings.Join(left, ", ")+".")
+	}
+}
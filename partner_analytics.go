@@ -0,0 +1,349 @@
+package main
+
+import (
+	"bufio"
+	"bytes"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/csv"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"log"
+	"os"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/bwmarrin/discordgo"
+)
+
+// --- Partner Analytics Section ---
+
+// Kinds of partner events recorded for analytics
+const (
+	partnerEventView   = "view"
+	partnerEventLink   = "link"
+	partnerEventRedeem = "redeem"
+	partnerEventClick  = "click"
+)
+
+// A single partner interaction. Users are stored as salted hashes, never raw IDs.
+type PartnerEvent struct {
+	Time     time.Time `json:"time"`
+	Partner  string    `json:"partner"`
+	Kind     string    `json:"kind"`
+	UserHash string    `json:"user_hash"`
+	Tier     string    `json:"tier"`
+	Link     string    `json:"link,omitempty"` // Label of the link followed, for click events
+}
+
+const (
+	partnerEventsFile  = "partner_events.jsonl"
+	analyticsSaltFile  = "analytics_salt"
+	statsExportMaxDays = 366
+)
+
+var (
+	partnerEventsMu sync.Mutex
+	analyticsSalt   []byte
+)
+
+// Load the hashing salt, creating one on first run so user hashes stay stable across restarts
+func loadAnalyticsSalt() error {
+	b, err := os.ReadFile(analyticsSaltFile)
+	if err == nil {
+		analyticsSalt = bytes.TrimSpace(b)
+		return nil
+	}
+	if !os.IsNotExist(err) {
+		return err
+	}
+	salt := make([]byte, 32)
+	if _, err := rand.Read(salt); err != nil {
+		return err
+	}
+	analyticsSalt = []byte(hex.EncodeToString(salt))
+	return os.WriteFile(analyticsSaltFile, analyticsSalt, 0600)
+}
+
+// Hash a Discord user ID so analytics can count unique users without storing who they are
+func hashUserID(userID string) string {
+	h := sha256.New()
+	h.Write(analyticsSalt)
+	h.Write([]byte(userID))
+	return hex.EncodeToString(h.Sum(nil))[:16]
+}
+
+// Classify a member by their highest relevant role
+func roleTier(roles []string) string {
+	switch {
+	case hasRole(roles, accessRoleID):
+		return "member"
+	case config.RoleFoundID != "" && hasRole(roles, config.RoleFoundID):
+		return "verified"
+	default:
+		return "guest"
+	}
+}
+
+// Record a partner event for a Discord member
+func recordPartnerEvent(partnerName, kind, userID string, roles []string) {
+	appendPartnerEvent(PartnerEvent{
+		Time:     time.Now().UTC(),
+		Partner:  partnerName,
+		Kind:     kind,
+		UserHash: hashUserID(userID),
+		Tier:     roleTier(roles),
+	})
+}
+
+// Append a partner event to the analytics log
+func appendPartnerEvent(ev PartnerEvent) {
+	b, err := json.Marshal(ev)
+	if err != nil {
+		log.Printf("Error encoding partner event: %v", err)
+		return
+	}
+	partnerEventsMu.Lock()
+	defer partnerEventsMu.Unlock()
+	f, err := os.OpenFile(partnerEventsFile, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0644)
+	if err != nil {
+		log.Printf("Error opening partner events file: %v", err)
+		return
+	}
+	defer f.Close()
+	if _, err := f.Write(append(b, '\n')); err != nil {
+		log.Printf("Error writing partner event: %v", err)
+	}
+}
+
+// Read all partner events recorded at or after since
+func readPartnerEvents(since time.Time) ([]PartnerEvent, error) {
+	partnerEventsMu.Lock()
+	defer partnerEventsMu.Unlock()
+	f, err := os.Open(partnerEventsFile)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	defer f.Close()
+	var events []PartnerEvent
+	scanner := bufio.NewScanner(f)
+	for scanner.Scan() {
+		var ev PartnerEvent
+		if err := json.Unmarshal(scanner.Bytes(), &ev); err != nil {
+			continue
+		}
+		if !ev.Time.Before(since) {
+			events = append(events, ev)
+		}
+	}
+	return events, scanner.Err()
+}
+
+// Aggregated counts for one partner over a window
+type partnerStats struct {
+	Views, Links, Clicks, Redeems int
+	Users                         map[string]bool
+}
+
+func (ps *partnerStats) add(ev PartnerEvent) {
+	switch ev.Kind {
+	case partnerEventView:
+		ps.Views++
+	case partnerEventLink:
+		ps.Links++
+	case partnerEventClick:
+		ps.Clicks++
+	case partnerEventRedeem:
+		ps.Redeems++
+	}
+	ps.Users[ev.UserHash] = true
+}
+
+func (ps *partnerStats) total() int {
+	return ps.Views + ps.Links + ps.Clicks + ps.Redeems
+}
+
+// Group events in [from, to) by partner
+func aggregatePartnerEvents(events []PartnerEvent, from, to time.Time) map[string]*partnerStats {
+	out := map[string]*partnerStats{}
+	for _, ev := range events {
+		if ev.Time.Before(from) || !ev.Time.Before(to) {
+			continue
+		}
+		ps, ok := out[ev.Partner]
+		if !ok {
+			ps = &partnerStats{Users: map[string]bool{}}
+			out[ev.Partner] = ps
+		}
+		ps.add(ev)
+	}
+	return out
+}
+
+// Format the change between two totals as a trend string
+func formatTrend(current, previous int) string {
+	switch {
+	case previous == 0 && current == 0:
+		return "→ no activity"
+	case previous == 0:
+		return "▲ new"
+	}
+	change := float64(current-previous) / float64(previous) * 100
+	switch {
+	case change > 0:
+		return fmt.Sprintf("▲ %.0f%%", change)
+	case change < 0:
+		return fmt.Sprintf("▼ %.0f%%", -change)
+	default:
+		return "→ 0%"
+	}
+}
+
+// Build a CSV of daily per-partner totals, suitable for partner reports
+func buildPartnerStatsCSV(events []PartnerEvent, from, to time.Time) ([]byte, error) {
+	type key struct{ day, partner string }
+	daily := map[key]*partnerStats{}
+	for _, ev := range events {
+		if ev.Time.Before(from) || !ev.Time.Before(to) {
+			continue
+		}
+		k := key{ev.Time.Format("2006-01-02"), ev.Partner}
+		ps, ok := daily[k]
+		if !ok {
+			ps = &partnerStats{Users: map[string]bool{}}
+			daily[k] = ps
+		}
+		ps.add(ev)
+	}
+	keys := make([]key, 0, len(daily))
+	for k := range daily {
+		keys = append(keys, k)
+	}
+	sort.Slice(keys, func(a, b int) bool {
+		if keys[a].day != keys[b].day {
+			return keys[a].day < keys[b].day
+		}
+		return keys[a].partner < keys[b].partner
+	})
+
+	var buf bytes.Buffer
+	w := csv.NewWriter(&buf)
+	w.Write([]string{"date", "partner", "views", "link_reveals", "clicks", "redemptions", "unique_users"})
+	for _, k := range keys {
+		ps := daily[k]
+		w.Write([]string{k.day, k.partner, fmt.Sprint(ps.Views), fmt.Sprint(ps.Links), fmt.Sprint(ps.Clicks), fmt.Sprint(ps.Redeems), fmt.Sprint(len(ps.Users))})
+	}
+	w.Flush()
+	return buf.Bytes(), w.Error()
+}
+
+// Handle /partnerstats: per-partner totals and trends, optionally as a CSV export
+func handlePartnerStatsCommand(s *discordgo.Session, i *discordgo.InteractionCreate) {
+	if !hasRole(i.Member.Roles, addPartnerRoleID) {
+		respondEphemeral(s, i, "You do not have permission to use this command.")
+		return
+	}
+	opts := optionMap(i.ApplicationCommandData().Options)
+	days := 30
+	if opt, ok := opts["days"]; ok {
+		days = int(opt.IntValue())
+	}
+	if days < 1 || days > statsExportMaxDays {
+		respondEphemeral(s, i, fmt.Sprintf("Days must be between 1 and %d.", statsExportMaxDays))
+		return
+	}
+	now := time.Now().UTC()
+	window := time.Duration(days) * 24 * time.Hour
+	from := now.Add(-window)
+	prevFrom := from.Add(-window)
+
+	events, err := readPartnerEvents(prevFrom)
+	if err != nil {
+		respondEphemeral(s, i, "Failed to read partner analytics: "+err.Error())
+		return
+	}
+
+	if opt, ok := opts["export"]; ok && opt.BoolValue() {
+		b, err := buildPartnerStatsCSV(events, from, now)
+		if err != nil {
+			respondEphemeral(s, i, "Failed to build CSV export: "+err.Error())
+			return
+		}
+		filename := fmt.Sprintf("partner-stats-%s-to-%s.csv", from.Format("2006-01-02"), now.Format("2006-01-02"))
+		s.InteractionRespond(i.Interaction, &discordgo.InteractionResponse{
+			Type: discordgo.InteractionResponseChannelMessageWithSource,
+			Data: &discordgo.InteractionResponseData{
+				Content: fmt.Sprintf("Partner analytics for the last %d day(s).", days),
+				Files:   []*discordgo.File{{Name: filename, ContentType: "text/csv", Reader: bytes.NewReader(b)}},
+				Flags:   discordgo.MessageFlagsEphemeral,
+			},
+		})
+		return
+	}
+
+	current := aggregatePartnerEvents(events, from, now)
+	previous := aggregatePartnerEvents(events, prevFrom, from)
+	names := make([]string, 0, len(current))
+	for name := range current {
+		names = append(names, name)
+	}
+	for name := range previous {
+		if _, ok := current[name]; !ok {
+			names = append(names, name)
+		}
+	}
+	sort.Slice(names, func(a, b int) bool {
+		ta, tb := 0, 0
+		if ps, ok := current[names[a]]; ok {
+			ta = ps.total()
+		}
+		if ps, ok := current[names[b]]; ok {
+			tb = ps.total()
+		}
+		if ta != tb {
+			return ta > tb
+		}
+		return strings.ToLower(names[a]) < strings.ToLower(names[b])
+	})
+	if len(names) > 25 {
+		names = names[:25]
+	}
+
+	var fields []*discordgo.MessageEmbedField
+	for _, name := range names {
+		cur, ok := current[name]
+		if !ok {
+			cur = &partnerStats{Users: map[string]bool{}}
+		}
+		prevTotal := 0
+		if ps, ok := previous[name]; ok {
+			prevTotal = ps.total()
+		}
+		fields = append(fields, &discordgo.MessageEmbedField{
+			Name: name,
+			Value: fmt.Sprintf("Views: %d · Link reveals: %d · Clicks: %d · Redemptions: %d\nUnique members: %d · Trend: %s",
+				cur.Views, cur.Links, cur.Clicks, cur.Redeems, len(cur.Users), formatTrend(cur.total(), prevTotal)),
+		})
+	}
+	embed := &discordgo.MessageEmbed{
+		Title:       "Partner Stats",
+		Description: fmt.Sprintf("Last %d day(s), compared with the %d day(s) before.", days, days),
+		Fields:      fields,
+	}
+	if len(fields) == 0 {
+		embed.Description += "\n\nNo partner activity recorded in this period."
+	}
+	s.InteractionRespond(i.Interaction, &discordgo.InteractionResponse{
+		Type: discordgo.InteractionResponseChannelMessageWithSource,
+		Data: &discordgo.InteractionResponseData{
+			Embeds: []*discordgo.MessageEmbed{embed},
+			Flags:  discordgo.MessageFlagsEphemeral,
+		},
+	})
+}
@@ -0,0 +1,178 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"log"
+	"time"
+
+	"github.com/bwmarrin/discordgo"
+)
+
+// --- Partner Announcements Section ---
+
+// Announcement waiting for a scheduled partner to go live
+type PartnerAnnouncement struct {
+	PingRoleID string `json:"ping_role_id,omitempty"`
+	Attempts   int    `json:"attempts,omitempty"` // Failed posts so far
+}
+
+// Failed posts after which a queued announcement is dropped
+const maxPendingAnnouncementAttempts = 5
+
+var errNoAnnouncementsChannel = errors.New("no partnerAnnouncementsChannelId is configured")
+
+// Post an announcement embed for a partner, optionally pinging a role
+func postPartnerAnnouncement(s *discordgo.Session, p *Partner, pingRoleID string) error {
+	if config.PartnerAnnouncementsChannelID == "" {
+		return errNoAnnouncementsChannel
+	}
+	embed := &discordgo.MessageEmbed{
+		Title:       "New Partner: " + p.Name,
+		Description: p.Description,
+		Fields: []*discordgo.MessageEmbedField{
+			{Name: "Offering", Value: truncateRunes(p.Offering, maxEmbedFieldValueLength), Inline: false},
+		},
+	}
+	thumbnail, logoFile := partnerThumbnail(p)
+	embed.Thumbnail = thumbnail
+	decoratePartnerEmbed(embed, p)
+	embed.Footer = &discordgo.MessageEmbedFooter{Text: "Press the button below to see how to access this offering."}
+	name, id, animated := parseEmoji(p.Emoji)
+	msg := &discordgo.MessageSend{
+		Embeds: []*discordgo.MessageEmbed{embed},
+		Components: []discordgo.MessageComponent{
+			discordgo.ActionsRow{Components: []discordgo.MessageComponent{
+				discordgo.Button{
+					Label:    "View Offering",
+					Emoji:    &discordgo.ComponentEmoji{Name: name, ID: id, Animated: animated},
+					CustomID: "partner_" + p.Name,
+					Style:    discordgo.PrimaryButton,
+				},
+			}},
+		},
+		AllowedMentions: &discordgo.MessageAllowedMentions{},
+	}
+	if logoFile != nil {
+		msg.Files = []*discordgo.File{logoFile}
+	}
+	if pingRoleID != "" {
+		msg.Content = "<@&" + pingRoleID + ">"
+		msg.AllowedMentions.Roles = []string{pingRoleID}
+	}
+	_, err := s.ChannelMessageSendComplex(config.PartnerAnnouncementsChannelID, msg)
+	return err
+}
+
+// Resolve the role to ping from announce/ping/ping_role options. Returns "" for a quiet announcement.
+func announcementPingRole(opts map[string]*discordgo.ApplicationCommandInteractionDataOption) string {
+	if opt, ok := opts["ping"]; !ok || !opt.BoolValue() {
+		return ""
+	}
+	if opt, ok := opts["ping_role"]; ok {
+		return opt.Value.(string)
+	}
+	return config.PartnerAnnouncementRoleID
+}
+
+// Announce a partner now if it's live, or queue the announcement for when it goes live.
+// Returns a short note for the admin's reply. Takes partnersMu itself and posts
+// after releasing it, so callers must not hold it.
+func announceOrQueuePartner(s *discordgo.Session, name, pingRoleID string) string {
+	partnersMu.Lock()
+	p := findPartner(name)
+	if p == nil {
+		partnersMu.Unlock()
+		return " The partner was removed before it could be announced."
+	}
+	if !p.isLive(time.Now()) {
+		p.PendingAnnouncement = &PartnerAnnouncement{PingRoleID: pingRoleID}
+		if err := savePartners(); err != nil {
+			log.Printf("Error saving partners: %v", err)
+		}
+		partnersMu.Unlock()
+		return " The announcement will be posted when the partner goes live."
+	}
+	live := *p
+	partnersMu.Unlock()
+	if err := postPartnerAnnouncement(s, &live, pingRoleID); err != nil {
+		log.Printf("Error posting partner announcement: %v", err)
+		return " The announcement couldn't be posted: " + err.Error()
+	}
+	return " Announcement posted."
+}
+
+// Post queued announcements for partners that have gone live. Failed posts are
+// retried on later passes; an announcement that keeps failing, or fails in a way
+// a retry can't fix, is dropped and admins are told. Takes partnersMu itself and
+// holds it only while reading and updating the queue, never while posting.
+func publishPendingAnnouncements(s *discordgo.Session, now time.Time) {
+	partnersMu.RLock()
+	var due []Partner
+	for _, p := range partners {
+		if p.PendingAnnouncement != nil && p.isLive(now) {
+			due = append(due, p)
+		}
+	}
+	partnersMu.RUnlock()
+	if len(due) == 0 {
+		return
+	}
+	results := make([]error, len(due))
+	for idx := range due {
+		results[idx] = postPartnerAnnouncement(s, &due[idx], due[idx].PendingAnnouncement.PingRoleID)
+	}
+
+	var alerts []string
+	partnersMu.Lock()
+	for idx, err := range results {
+		// The partner may have been removed, or the announcement cleared or
+		// replaced, while posting
+		p := findPartner(due[idx].Name)
+		if p == nil || p.PendingAnnouncement != due[idx].PendingAnnouncement {
+			continue
+		}
+		if err == nil {
+			p.PendingAnnouncement = nil
+			continue
+		}
+		log.Printf("Error posting scheduled partner announcement for %s: %v", p.Name, err)
+		// Revisions share the pointer with the live partner, so replace rather than mutate it
+		retry := *p.PendingAnnouncement
+		retry.Attempts++
+		p.PendingAnnouncement = &retry
+		if retry.Attempts < maxPendingAnnouncementAttempts &&
+			!errors.Is(err, errNoAnnouncementsChannel) && !isPermanentDiscordError(err) {
+			continue
+		}
+		p.PendingAnnouncement = nil
+		alerts = append(alerts, fmt.Sprintf("⚠️ The queued announcement for %s could not be posted and was dropped: %v. Use /announcepartner to try again.", p.Name, err))
+	}
+	if err := savePartners(); err != nil {
+		log.Printf("Error saving partners: %v", err)
+	}
+	partnersMu.Unlock()
+	for _, alert := range alerts {
+		sendAdminAlert(s, alert)
+	}
+}
+
+// Handle /announcepartner: post an announcement for an existing partner
+func handleAnnouncePartnerCommand(s *discordgo.Session, i *discordgo.InteractionCreate) {
+	if !hasRole(i.Member.Roles, addPartnerRoleID) {
+		respondEphemeral(s, i, "You do not have permission to use this command.")
+		return
+	}
+	opts := optionMap(i.ApplicationCommandData().Options)
+	partnersMu.RLock()
+	var name string
+	if p := findPartner(opts["name"].StringValue()); p != nil {
+		name = p.Name
+	}
+	partnersMu.RUnlock()
+	if name == "" {
+		respondEphemeral(s, i, "Partner not found.")
+		return
+	}
+	respondEphemeral(s, i, "Announcing "+name+"."+announceOrQueuePartner(s, name, announcementPingRole(opts)))
+}
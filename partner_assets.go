@@ -0,0 +1,288 @@
+package main
+
+import (
+	"bytes"
+	"crypto/rand"
+	"encoding/base64"
+	"encoding/hex"
+	"fmt"
+	"image"
+	_ "image/gif"
+	_ "image/jpeg"
+	"image/png"
+	"log"
+	"os"
+	"path/filepath"
+	"regexp"
+	"slices"
+	"strings"
+
+	"github.com/bwmarrin/discordgo"
+)
+
+// --- Partner Logo Assets Section ---
+
+const (
+	partnerAssetsDir      = "partner_assets"
+	maxLogoUploadSize     = 8 << 20
+	maxLogoDimension      = 512
+	maxSourceDimension    = 4096
+	partnerEmojiDimension = 128
+	maxEmojiSize          = 256 << 10
+	logoAttachmentName    = "logo.png"
+)
+
+var nonSlugChars = regexp.MustCompile(`[^a-z0-9]+`)
+
+// File-system and emoji safe name for a partner
+func partnerSlug(name string) string {
+	slug := strings.Trim(nonSlugChars.ReplaceAllString(strings.ToLower(name), "_"), "_")
+	if slug == "" {
+		slug = "partner"
+	}
+	return slug
+}
+
+// Shrink an image so neither side exceeds maxDim, keeping its aspect ratio
+func scaleImage(src image.Image, maxDim int) image.Image {
+	b := src.Bounds()
+	w, h := b.Dx(), b.Dy()
+	if w <= maxDim && h <= maxDim {
+		return src
+	}
+	nw, nh := maxDim, h*maxDim/w
+	if h > w {
+		nw, nh = w*maxDim/h, maxDim
+	}
+	nw, nh = max(nw, 1), max(nh, 1)
+	dst := image.NewRGBA(image.Rect(0, 0, nw, nh))
+	for y := 0; y < nh; y++ {
+		for x := 0; x < nw; x++ {
+			dst.Set(x, y, src.At(b.Min.X+x*w/nw, b.Min.Y+y*h/nh))
+		}
+	}
+	return dst
+}
+
+// Check an uploaded logo's type and size as reported by Discord, before downloading it
+func checkLogoAttachment(att *discordgo.MessageAttachment) error {
+	switch strings.ToLower(strings.Split(att.ContentType, ";")[0]) {
+	case "image/png", "image/jpeg", "image/gif":
+	case "image/svg+xml":
+		return fmt.Errorf("SVG images don't render in Discord; upload a PNG, JPEG or GIF")
+	default:
+		return fmt.Errorf("unsupported file type %q; upload a PNG, JPEG or GIF", att.ContentType)
+	}
+	if att.Width > maxSourceDimension || att.Height > maxSourceDimension {
+		return fmt.Errorf("image is %dx%d; the limit is %dx%d", att.Width, att.Height, maxSourceDimension, maxSourceDimension)
+	}
+	if att.Size > maxLogoUploadSize {
+		return fmt.Errorf("file is too large (max %d KB)", maxLogoUploadSize/1024)
+	}
+	return nil
+}
+
+// Download and decode an uploaded logo, checking its type and size
+func decodeLogoUpload(att *discordgo.MessageAttachment) (image.Image, error) {
+	if err := checkLogoAttachment(att); err != nil {
+		return nil, err
+	}
+	raw, err := downloadAttachment(att, maxLogoUploadSize)
+	if err != nil {
+		return nil, err
+	}
+	cfg, _, err := image.DecodeConfig(bytes.NewReader(raw))
+	if err != nil {
+		return nil, fmt.Errorf("couldn't read the image: %v", err)
+	}
+	if cfg.Width > maxSourceDimension || cfg.Height > maxSourceDimension {
+		return nil, fmt.Errorf("image is %dx%d; the limit is %dx%d", cfg.Width, cfg.Height, maxSourceDimension, maxSourceDimension)
+	}
+	img, _, err := image.Decode(bytes.NewReader(raw))
+	if err != nil {
+		return nil, fmt.Errorf("couldn't read the image: %v", err)
+	}
+	return img, nil
+}
+
+// Encode an image as PNG
+func encodePNG(img image.Image) ([]byte, error) {
+	var buf bytes.Buffer
+	if err := png.Encode(&buf, img); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+// Upload a logo as a guild emoji
+func uploadPartnerEmoji(s *discordgo.Session, partnerName string, img image.Image) (*discordgo.Emoji, error) {
+	b, err := encodePNG(scaleImage(img, partnerEmojiDimension))
+	if err != nil {
+		return nil, err
+	}
+	if len(b) > maxEmojiSize {
+		return nil, fmt.Errorf("the emoji image is over 256 KB after resizing")
+	}
+	// Emoji names must be 2-32 characters
+	name := partnerSlug(partnerName)
+	if len(name) > 32 {
+		name = name[:32]
+	}
+	if len(name) < 2 {
+		name += "_logo"
+	}
+	return s.GuildEmojiCreate(config.GuildID, &discordgo.EmojiParams{
+		Name:  name,
+		Image: "data:image/png;base64," + base64.StdEncoding.EncodeToString(b),
+	})
+}
+
+// A logo_file upload for /addpartner or /editpartner. The image is only
+// downloaded once the rest of the partner validates, is written beside its
+// final path, and is moved into place after partners.json has been saved.
+type partnerLogoUpload struct {
+	att         *discordgo.MessageAttachment
+	uploadEmoji bool
+	path        string // Where the logo ends up; already set as the partner's LogoFile
+	tmpPath     string
+	emojiID     string
+}
+
+// Read the logo_file and upload_emoji options without downloading anything,
+// pointing the partner's LogoFile at where the logo will be stored.
+// Returns nil when no logo is attached. Must run after the partner's name is set.
+func parsePartnerLogoOptions(i *discordgo.InteractionCreate, p *Partner, opts map[string]*discordgo.ApplicationCommandInteractionDataOption) (*partnerLogoUpload, error) {
+	uploadEmoji := false
+	if opt, ok := opts["upload_emoji"]; ok {
+		uploadEmoji = opt.BoolValue()
+	}
+	opt, ok := opts["logo_file"]
+	if !ok {
+		if uploadEmoji {
+			return nil, fmt.Errorf("upload_emoji: attach a logo_file to create the emoji from")
+		}
+		return nil, nil
+	}
+	att := i.ApplicationCommandData().Resolved.Attachments[opt.Value.(string)]
+	if att == nil {
+		return nil, fmt.Errorf("logo_file: could not read the attached file")
+	}
+	if err := checkLogoAttachment(att); err != nil {
+		return nil, fmt.Errorf("logo_file: %v", err)
+	}
+	// Names like "A&B" and "A B" share a slug, and an edit must not touch the
+	// file the saved partner still points at, so every upload gets a new name
+	suffix := make([]byte, 4)
+	rand.Read(suffix)
+	l := &partnerLogoUpload{
+		att:         att,
+		uploadEmoji: uploadEmoji,
+		path:        filepath.Join(partnerAssetsDir, partnerSlug(p.Name)+"_"+hex.EncodeToString(suffix)+".png"),
+	}
+	p.LogoFile = l.path
+	return l, nil
+}
+
+// Drop problems with the partner's emoji when an uploaded emoji will replace it
+func (l *partnerLogoUpload) filterErrors(errs []partnerFieldError) []partnerFieldError {
+	if l == nil || !l.uploadEmoji {
+		return errs
+	}
+	return slices.DeleteFunc(errs, func(e partnerFieldError) bool { return e.Field == "emoji" })
+}
+
+// Download the logo into a temporary file and upload the emoji if asked to.
+// Anything already created is removed again when a step fails.
+func (l *partnerLogoUpload) prepare(s *discordgo.Session, p *Partner) error {
+	if l == nil {
+		return nil
+	}
+	img, err := decodeLogoUpload(l.att)
+	if err != nil {
+		return fmt.Errorf("logo_file: %v", err)
+	}
+	b, err := encodePNG(scaleImage(img, maxLogoDimension))
+	if err != nil {
+		return fmt.Errorf("logo_file: couldn't convert the image: %v", err)
+	}
+	if err := os.MkdirAll(partnerAssetsDir, 0755); err != nil {
+		return fmt.Errorf("logo_file: couldn't save the image: %v", err)
+	}
+	if err := writeFileAtomic(l.path+".tmp", b, 0644); err != nil {
+		return fmt.Errorf("logo_file: couldn't save the image: %v", err)
+	}
+	l.tmpPath = l.path + ".tmp"
+	if l.uploadEmoji {
+		emoji, err := uploadPartnerEmoji(s, p.Name, img)
+		if err != nil {
+			l.discard(s)
+			return fmt.Errorf("upload_emoji: %v", err)
+		}
+		l.emojiID = emoji.ID
+		p.Emoji = emoji.MessageFormat()
+	}
+	return nil
+}
+
+// Move the logo into place once the partner has been saved
+func (l *partnerLogoUpload) commit() error {
+	if l == nil || l.tmpPath == "" {
+		return nil
+	}
+	err := os.Rename(l.tmpPath, l.path)
+	if err != nil {
+		os.Remove(l.tmpPath)
+	}
+	l.tmpPath = ""
+	return err
+}
+
+// Remove the temporary logo and any uploaded emoji after the partner wasn't saved
+func (l *partnerLogoUpload) discard(s *discordgo.Session) {
+	if l == nil {
+		return
+	}
+	if l.tmpPath != "" {
+		if err := os.Remove(l.tmpPath); err != nil {
+			log.Printf("Error removing partner logo %s: %v", l.tmpPath, err)
+		}
+		l.tmpPath = ""
+	}
+	if l.emojiID != "" {
+		if err := s.GuildEmojiDelete(config.GuildID, l.emojiID); err != nil {
+			log.Printf("Error deleting partner emoji %s: %v", l.emojiID, err)
+		}
+		l.emojiID = ""
+	}
+}
+
+// Resolve a partner's LogoFile to a file in partnerAssetsDir. Anything else,
+// such as a path edited into partners.json, is refused so it's never sent to Discord.
+func storedLogoPath(logoFile string) (string, error) {
+	path := filepath.Join(partnerAssetsDir, filepath.Base(logoFile))
+	if filepath.Clean(logoFile) != path || !strings.HasPrefix(path, partnerAssetsDir+string(filepath.Separator)) {
+		return "", fmt.Errorf("logo files must be stored in %s", partnerAssetsDir)
+	}
+	return path, nil
+}
+
+// Thumbnail for a partner embed. Stored logos are sent as an attachment, so
+// the returned file must be included with the message when it isn't nil.
+func partnerThumbnail(p *Partner) (*discordgo.MessageEmbedThumbnail, *discordgo.File) {
+	if p.LogoFile != "" {
+		path, err := storedLogoPath(p.LogoFile)
+		var b []byte
+		if err == nil {
+			b, err = os.ReadFile(path)
+		}
+		if err == nil {
+			return &discordgo.MessageEmbedThumbnail{URL: "attachment://" + logoAttachmentName},
+				&discordgo.File{Name: logoAttachmentName, ContentType: "image/png", Reader: bytes.NewReader(b)}
+		}
+		log.Printf("Error reading partner logo %s: %v", p.LogoFile, err)
+	}
+	if p.LogoURL == "" {
+		return nil, nil
+	}
+	return &discordgo.MessageEmbedThumbnail{URL: p.LogoURL}, nil
+}
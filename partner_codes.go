@@ -0,0 +1,358 @@
+package main
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"io"
+	"log"
+	"net/http"
+	"os"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/bwmarrin/discordgo"
+)
+
+// --- Partner Code Pool Section ---
+
+// Single-use redemption codes for one partner
+type PartnerCodePool struct {
+	Codes             []string          `json:"codes"`    // Unassigned codes, handed out in order
+	Assigned          map[string]string `json:"assigned"` // Discord user ID -> code
+	LowStockThreshold int               `json:"low_stock_threshold"`
+	LowStockAlerted   bool              `json:"low_stock_alerted"`
+}
+
+var (
+	partnerCodePools = map[string]*PartnerCodePool{}
+	partnerCodesMu   sync.Mutex
+	attachmentClient = &http.Client{Timeout: 30 * time.Second}
+)
+
+const (
+	partnerCodesFile         = "partner_codes.json"
+	defaultLowStockThreshold = 10
+	maxCodeFileSize          = 1 << 20
+)
+
+// Load partner code pools from file
+func loadPartnerCodes() error {
+	b, err := os.ReadFile(partnerCodesFile)
+	if err != nil {
+		if os.IsNotExist(err) {
+			partnerCodePools = map[string]*PartnerCodePool{}
+			return nil
+		}
+		return err
+	}
+	return json.Unmarshal(b, &partnerCodePools)
+}
+
+// Save partner code pools to file. Callers must hold partnerCodesMu.
+func savePartnerCodes() error {
+	b, err := json.MarshalIndent(partnerCodePools, "", "  ")
+	if err != nil {
+		return err
+	}
+	return writeFileAtomic(partnerCodesFile, b, 0600)
+}
+
+// Report whether a partner has a code pool configured
+func hasCodePool(partnerName string) bool {
+	partnerCodesMu.Lock()
+	defer partnerCodesMu.Unlock()
+	_, ok := partnerCodePools[partnerName]
+	return ok
+}
+
+// Assign a code to a user, returning their existing code on repeat presses.
+// The code is empty when the pool has run out; isNew is false for a repeat press.
+func assignPartnerCode(s *discordgo.Session, partnerName, userID string) (code string, isNew bool, err error) {
+	// Deferred before the lock is taken so the alert is sent after it's released
+	lowStockAlert := ""
+	defer func() {
+		if lowStockAlert != "" {
+			sendAdminAlert(s, lowStockAlert)
+		}
+	}()
+	partnerCodesMu.Lock()
+	defer partnerCodesMu.Unlock()
+
+	pool, ok := partnerCodePools[partnerName]
+	if !ok {
+		return "", false, nil
+	}
+	if code, ok := pool.Assigned[userID]; ok {
+		return code, false, nil
+	}
+	if len(pool.Codes) == 0 {
+		return "", false, nil
+	}
+	code = pool.Codes[0]
+	pool.Codes = pool.Codes[1:]
+	if pool.Assigned == nil {
+		pool.Assigned = map[string]string{}
+	}
+	pool.Assigned[userID] = code
+	if err := savePartnerCodes(); err != nil {
+		// Roll back so the code isn't lost if the file can't be written
+		delete(pool.Assigned, userID)
+		pool.Codes = append([]string{code}, pool.Codes...)
+		return "", false, err
+	}
+
+	if len(pool.Codes) <= pool.LowStockThreshold && !pool.LowStockAlerted {
+		pool.LowStockAlerted = true
+		if err := savePartnerCodes(); err != nil {
+			log.Printf("Error saving partner codes: %v", err)
+		}
+		lowStockAlert = fmt.Sprintf("⚠️ %s has only %d redemption code(s) left. Upload more with /uploadpartnercodes.", partnerName, len(pool.Codes))
+	}
+	return code, true, nil
+}
+
+// Post a message to the configured admin channel, if any. Without one the alert
+// is only logged, which counts as delivered.
+func sendAdminAlert(s *discordgo.Session, content string) error {
+	if config.AdminChannelID == "" {
+		log.Printf("Admin alert (no admin channel configured): %s", content)
+		return nil
+	}
+	_, err := s.ChannelMessageSend(config.AdminChannelID, content)
+	if err != nil {
+		log.Printf("Error sending admin alert: %v", err)
+	}
+	return err
+}
+
+// Download a slash command attachment, refusing anything over maxSize bytes
+func downloadAttachment(att *discordgo.MessageAttachment, maxSize int) ([]byte, error) {
+	if att.Size > maxSize {
+		return nil, fmt.Errorf("file is too large (max %d KB)", maxSize/1024)
+	}
+	resp, err := attachmentClient.Get(att.URL)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("download failed: %s", resp.Status)
+	}
+	b, err := io.ReadAll(io.LimitReader(resp.Body, int64(maxSize)+1))
+	if err != nil {
+		return nil, err
+	}
+	if len(b) > maxSize {
+		return nil, fmt.Errorf("file is too large (max %d KB)", maxSize/1024)
+	}
+	return b, nil
+}
+
+// Parse codes from a text file (one per line) or a CSV file (first column)
+func parseCodeList(filename string, data []byte) ([]string, error) {
+	var codes []string
+	if strings.HasSuffix(strings.ToLower(filename), ".csv") {
+		records, err := csv.NewReader(bytes.NewReader(data)).ReadAll()
+		if err != nil {
+			return nil, err
+		}
+		for idx, rec := range records {
+			if len(rec) == 0 {
+				continue
+			}
+			code := strings.TrimSpace(rec[0])
+			if idx == 0 && strings.EqualFold(code, "code") {
+				continue
+			}
+			if code != "" {
+				codes = append(codes, code)
+			}
+		}
+		return codes, nil
+	}
+	scanner := bufio.NewScanner(bytes.NewReader(data))
+	for scanner.Scan() {
+		if code := strings.TrimSpace(scanner.Text()); code != "" {
+			codes = append(codes, code)
+		}
+	}
+	return codes, scanner.Err()
+}
+
+// Handle /uploadpartnercodes: add codes from an attachment to a partner's pool
+func handleUploadPartnerCodesCommand(s *discordgo.Session, i *discordgo.InteractionCreate) {
+	if !hasRole(i.Member.Roles, addPartnerRoleID) {
+		respondEphemeral(s, i, "You do not have permission to use this command.")
+		return
+	}
+	data := i.ApplicationCommandData()
+	opts := optionMap(data.Options)
+	partnersMu.RLock()
+	p := findPartner(strings.TrimSpace(opts["name"].StringValue()))
+	partnerName := ""
+	if p != nil {
+		partnerName = p.Name
+	}
+	partnersMu.RUnlock()
+	if partnerName == "" {
+		respondEphemeral(s, i, "Partner not found.")
+		return
+	}
+	att := data.Resolved.Attachments[opts["file"].Value.(string)]
+	if att == nil {
+		respondEphemeral(s, i, "Could not read the attached file.")
+		return
+	}
+
+	// Downloading the file can outlast the interaction deadline
+	s.InteractionRespond(i.Interaction, &discordgo.InteractionResponse{
+		Type: discordgo.InteractionResponseDeferredChannelMessageWithSource,
+		Data: &discordgo.InteractionResponseData{Flags: discordgo.MessageFlagsEphemeral},
+	})
+	reply := func(content string) {
+		s.InteractionResponseEdit(i.Interaction, &discordgo.WebhookEdit{Content: &content})
+	}
+	raw, err := downloadAttachment(att, maxCodeFileSize)
+	if err != nil {
+		reply("Could not download the attached file: " + err.Error())
+		return
+	}
+	codes, err := parseCodeList(att.Filename, raw)
+	if err != nil {
+		reply("Could not parse the attached file: " + err.Error())
+		return
+	}
+
+	partnerCodesMu.Lock()
+	defer partnerCodesMu.Unlock()
+	pool, ok := partnerCodePools[partnerName]
+	if !ok {
+		pool = &PartnerCodePool{Assigned: map[string]string{}, LowStockThreshold: defaultLowStockThreshold}
+		partnerCodePools[partnerName] = pool
+	}
+	if opt, ok := opts["replace"]; ok && opt.BoolValue() {
+		pool.Codes = nil
+	}
+	if opt, ok := opts["threshold"]; ok {
+		pool.LowStockThreshold = int(opt.IntValue())
+	}
+
+	// Skip codes that are already in the pool or have been handed out
+	seen := map[string]bool{}
+	for _, c := range pool.Codes {
+		seen[c] = true
+	}
+	for _, c := range pool.Assigned {
+		seen[c] = true
+	}
+	added, skipped := 0, 0
+	for _, c := range codes {
+		if seen[c] {
+			skipped++
+			continue
+		}
+		seen[c] = true
+		pool.Codes = append(pool.Codes, c)
+		added++
+	}
+	if len(pool.Codes) > pool.LowStockThreshold {
+		pool.LowStockAlerted = false
+	}
+	if err := savePartnerCodes(); err != nil {
+		reply("Failed to save codes: " + err.Error())
+		return
+	}
+	reply(fmt.Sprintf("Added %d code(s) to %s (%d duplicate(s) skipped). %d available, %d assigned.",
+		added, partnerName, skipped, len(pool.Codes), len(pool.Assigned)))
+}
+
+// Handle /partnercodes: show code stock for every partner with a pool
+func handlePartnerCodesCommand(s *discordgo.Session, i *discordgo.InteractionCreate) {
+	if !hasRole(i.Member.Roles, addPartnerRoleID) {
+		respondEphemeral(s, i, "You do not have permission to use this command.")
+		return
+	}
+	partnerCodesMu.Lock()
+	names := make([]string, 0, len(partnerCodePools))
+	for name := range partnerCodePools {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	var fields []*discordgo.MessageEmbedField
+	for _, name := range names {
+		pool := partnerCodePools[name]
+		status := ""
+		if len(pool.Codes) <= pool.LowStockThreshold {
+			status = " ⚠️ low stock"
+		}
+		fields = append(fields, &discordgo.MessageEmbedField{
+			Name:   name,
+			Value:  fmt.Sprintf("%d available, %d assigned (alert at %d)%s", len(pool.Codes), len(pool.Assigned), pool.LowStockThreshold, status),
+			Inline: false,
+		})
+	}
+	partnerCodesMu.Unlock()
+
+	if len(fields) == 0 {
+		respondEphemeral(s, i, "No partner code pools configured yet.")
+		return
+	}
+	s.InteractionRespond(i.Interaction, &discordgo.InteractionResponse{
+		Type: discordgo.InteractionResponseChannelMessageWithSource,
+		Data: &discordgo.InteractionResponseData{
+			Embeds: []*discordgo.MessageEmbed{{Title: "Partner Codes", Fields: fields}},
+			Flags:  discordgo.MessageFlagsEphemeral,
+		},
+	})
+}
+
+// Handle the "Redeem" button on a partner's offering
+func handlePartnerRedeemButton(s *discordgo.Session, i *discordgo.InteractionCreate) {
+	partnerName := strings.TrimPrefix(i.MessageComponentData().CustomID, "partner_redeem_")
+	partnersMu.RLock()
+	var p *Partner
+	for _, partner := range partners {
+		if partner.Name == partnerName {
+			p = &partner
+			break
+		}
+	}
+	partnersMu.RUnlock()
+	if p == nil || !p.isLive(time.Now()) {
+		respondEphemeral(s, i, "This partner offering isn't currently available.")
+		return
+	}
+	userID, roles := interactionMember(s, i)
+	if !p.isEligible(roles) {
+		respondEphemeral(s, i, p.notEligibleMessage())
+		return
+	}
+	code, isNew, err := assignPartnerCode(s, p.Name, userID)
+	if err != nil {
+		log.Printf("Error assigning partner code: %v", err)
+		respondEphemeral(s, i, "Something went wrong while fetching your code. Please contact an admin.")
+		return
+	}
+	if code == "" {
+		respondEphemeral(s, i, fmt.Sprintf("All codes for %s have been claimed. Please check back later.", p.Name))
+		return
+	}
+	if isNew {
+		recordPartnerEvent(p.Name, partnerEventRedeem, userID, roles)
+	}
+	s.InteractionRespond(i.Interaction, &discordgo.InteractionResponse{
+		Type: discordgo.InteractionResponseChannelMessageWithSource,
+		Data: &discordgo.InteractionResponseData{
+			Embeds: []*discordgo.MessageEmbed{{
+				Title:       p.Name + " Code",
+				Description: fmt.Sprintf("Your code: `%s`\n\nThis code is reserved for you. Pressing Redeem again will show the same code.", code),
+			}},
+			Flags: discordgo.MessageFlagsEphemeral,
+		},
+	})
+}
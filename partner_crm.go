@@ -0,0 +1,171 @@
+package main
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/bwmarrin/discordgo"
+)
+
+// --- Partner CRM Section ---
+
+// Private partner details for admins. Never rendered in member-facing embeds.
+type PartnerAdminInfo struct {
+	ContactName  string     `json:"contact_name,omitempty"`
+	ContactEmail string     `json:"contact_email,omitempty"`
+	ContractRef  string     `json:"contract_ref,omitempty"`
+	RenewalDate  *time.Time `json:"renewal_date,omitempty"`
+	Notes        string     `json:"notes,omitempty"`
+}
+
+const defaultRenewalReminderDays = 30
+
+// Days of notice admins get before a contract renewal date
+func renewalReminderDays() int {
+	if config.RenewalReminderDays > 0 {
+		return config.RenewalReminderDays
+	}
+	return defaultRenewalReminderDays
+}
+
+// Remind admins about contracts coming up for renewal, given a copy of the current partners
+func sendRenewalReminders(s *discordgo.Session, current []Partner, now time.Time) {
+	notice := time.Duration(renewalReminderDays()) * 24 * time.Hour
+	for _, p := range current {
+		if p.Admin == nil || p.Admin.RenewalDate == nil {
+			continue
+		}
+		due := *p.Admin.RenewalDate
+		if !due.After(now) || due.Sub(now) > notice {
+			continue
+		}
+		contact := ""
+		if p.Admin.ContactName != "" || p.Admin.ContactEmail != "" {
+			contact = fmt.Sprintf(" Contact: %s %s.", p.Admin.ContactName, p.Admin.ContactEmail)
+		}
+		ref := ""
+		if p.Admin.ContractRef != "" {
+			ref = " (contract " + p.Admin.ContractRef + ")"
+		}
+		key := "renewal:" + p.Name + ":" + due.Format(time.RFC3339)
+		sendReminderOnce(s, key, fmt.Sprintf("📝 The %s contract%s is up for renewal <t:%d:R> (<t:%d:D>).%s",
+			p.Name, ref, due.Unix(), due.Unix(), strings.TrimRight(contact, " ")))
+	}
+}
+
+// Handle /partnerinfo: show a partner's private admin details
+func handlePartnerInfoCommand(s *discordgo.Session, i *discordgo.InteractionCreate) {
+	if !hasRole(i.Member.Roles, addPartnerRoleID) {
+		respondEphemeral(s, i, "You do not have permission to use this command.")
+		return
+	}
+	partnersMu.RLock()
+	var p *Partner
+	if found := findPartner(optionMap(i.ApplicationCommandData().Options)["name"].StringValue()); found != nil {
+		copied := *found
+		p = &copied
+	}
+	partnersMu.RUnlock()
+	if p == nil {
+		respondEphemeral(s, i, "Partner not found.")
+		return
+	}
+	info := p.Admin
+	if info == nil {
+		info = &PartnerAdminInfo{}
+	}
+	orDash := func(v string) string {
+		if v == "" {
+			return "-"
+		}
+		return v
+	}
+	renewal := "-"
+	if info.RenewalDate != nil {
+		renewal = fmt.Sprintf("<t:%d:D> (<t:%d:R>)", info.RenewalDate.Unix(), info.RenewalDate.Unix())
+	}
+	embed := &discordgo.MessageEmbed{
+		Title: p.Name + " (admin only)",
+		Fields: []*discordgo.MessageEmbedField{
+			{Name: "Contact", Value: orDash(info.ContactName), Inline: true},
+			{Name: "Email", Value: orDash(info.ContactEmail), Inline: true},
+			{Name: "Contract", Value: orDash(info.ContractRef), Inline: true},
+			{Name: "Renewal", Value: renewal, Inline: true},
+			{Name: "Notes", Value: truncateRunes(orDash(info.Notes), maxEmbedFieldValueLength)},
+		},
+	}
+	if sched := describePartnerSchedule(p); sched != "" {
+		embed.Description = strings.TrimSpace(sched)
+	}
+	s.InteractionRespond(i.Interaction, &discordgo.InteractionResponse{
+		Type: discordgo.InteractionResponseChannelMessageWithSource,
+		Data: &discordgo.InteractionResponseData{
+			Embeds: []*discordgo.MessageEmbed{embed},
+			Flags:  discordgo.MessageFlagsEphemeral,
+		},
+	})
+}
+
+// Handle /setpartnerinfo: update a partner's private admin details
+func handleSetPartnerInfoCommand(s *discordgo.Session, i *discordgo.InteractionCreate) {
+	if !hasRole(i.Member.Roles, addPartnerRoleID) {
+		respondEphemeral(s, i, "You do not have permission to use this command.")
+		return
+	}
+	opts := optionMap(i.ApplicationCommandData().Options)
+	partnersMu.Lock()
+	p := findPartner(opts["name"].StringValue())
+	if p == nil {
+		partnersMu.Unlock()
+		respondEphemeral(s, i, "Partner not found.")
+		return
+	}
+	info := PartnerAdminInfo{}
+	if p.Admin != nil {
+		info = *p.Admin
+	}
+	// "none" clears a field
+	for name, field := range map[string]*string{
+		"contact":  &info.ContactName,
+		"email":    &info.ContactEmail,
+		"contract": &info.ContractRef,
+		"notes":    &info.Notes,
+	} {
+		if opt, ok := opts[name]; ok {
+			*field = strings.TrimSpace(opt.StringValue())
+			if strings.EqualFold(*field, "none") {
+				*field = ""
+			}
+		}
+	}
+	if opt, ok := opts["renewal"]; ok {
+		if v := strings.TrimSpace(opt.StringValue()); strings.EqualFold(v, "none") {
+			info.RenewalDate = nil
+		} else {
+			t, err := parsePartnerTime(v)
+			if err != nil {
+				partnersMu.Unlock()
+				respondEphemeral(s, i, "renewal: "+err.Error())
+				return
+			}
+			info.RenewalDate = &t
+		}
+	}
+
+	previous := p.Admin
+	p.Admin = &info
+	if info == (PartnerAdminInfo{}) {
+		p.Admin = nil
+	}
+	if err := savePartners(); err != nil {
+		p.Admin = previous
+		partnersMu.Unlock()
+		respondEphemeral(s, i, "Failed to save partners: "+err.Error())
+		return
+	}
+	updated := *p
+	partnersMu.Unlock()
+	recordPartnerRevision(partnerActionUpdate, i.Member.User.ID, updated)
+	respondEphemeral(s, i, "Admin details for "+updated.Name+" updated.")
+}
@@ -0,0 +1,260 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"log"
+	"os"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/bwmarrin/discordgo"
+)
+
+// --- Partner Revision History Section ---
+
+// Actions recorded in partner history
+const (
+	partnerActionCreate  = "create"
+	partnerActionUpdate  = "update"
+	partnerActionDelete  = "delete"
+	partnerActionRestore = "restore"
+	partnerActionImport  = "import"
+)
+
+// A saved copy of a partner after a change
+type PartnerRevision struct {
+	ID       int       `json:"id"`
+	Partner  string    `json:"partner"`
+	Action   string    `json:"action"`
+	Actor    string    `json:"actor"`
+	Time     time.Time `json:"time"`
+	Snapshot Partner   `json:"snapshot"`
+}
+
+const (
+	partnerHistoryFile      = "partner_history.json"
+	partnerHistoryPageSize  = 10
+	partnerUndoCustomPrefix = "partnerundo_"
+)
+
+var (
+	partnerHistory   []PartnerRevision
+	partnerHistoryMu sync.Mutex
+)
+
+// Load partner history from file
+func loadPartnerHistory() error {
+	b, err := os.ReadFile(partnerHistoryFile)
+	if err != nil {
+		if os.IsNotExist(err) {
+			partnerHistory = []PartnerRevision{}
+			return nil
+		}
+		return err
+	}
+	return json.Unmarshal(b, &partnerHistory)
+}
+
+// Record a revision of a partner as it is after a change
+func recordPartnerRevision(action, actorID string, p Partner) {
+	partnerHistoryMu.Lock()
+	defer partnerHistoryMu.Unlock()
+	id := 1
+	if n := len(partnerHistory); n > 0 {
+		id = partnerHistory[n-1].ID + 1
+	}
+	partnerHistory = append(partnerHistory, PartnerRevision{
+		ID:       id,
+		Partner:  p.Name,
+		Action:   action,
+		Actor:    actorID,
+		Time:     time.Now().UTC(),
+		Snapshot: p,
+	})
+	b, err := json.MarshalIndent(partnerHistory, "", "  ")
+	if err != nil {
+		log.Printf("Error encoding partner history: %v", err)
+		return
+	}
+	if err := writeFileAtomic(partnerHistoryFile, b, 0644); err != nil {
+		log.Printf("Error saving partner history: %v", err)
+	}
+}
+
+// Revisions for a partner, newest first
+func partnerRevisions(name string) []PartnerRevision {
+	partnerHistoryMu.Lock()
+	defer partnerHistoryMu.Unlock()
+	var out []PartnerRevision
+	for idx := len(partnerHistory) - 1; idx >= 0; idx-- {
+		if strings.EqualFold(partnerHistory[idx].Partner, strings.TrimSpace(name)) {
+			out = append(out, partnerHistory[idx])
+		}
+	}
+	return out
+}
+
+// Find a soft-deleted partner by name
+func findDeletedPartner(name string) *Partner {
+	name = strings.TrimSpace(name)
+	for idx := range partners {
+		if partners[idx].DeletedAt != nil && strings.EqualFold(strings.TrimSpace(partners[idx].Name), name) {
+			return &partners[idx]
+		}
+	}
+	return nil
+}
+
+// Drop a soft-deleted partner so its name can be reused. History keeps its revisions.
+func purgeDeletedPartner(name string) {
+	for idx := range partners {
+		if partners[idx].DeletedAt != nil && strings.EqualFold(strings.TrimSpace(partners[idx].Name), strings.TrimSpace(name)) {
+			partners = append(partners[:idx], partners[idx+1:]...)
+			return
+		}
+	}
+}
+
+// Soft-delete a partner, keeping it in partners.json so it can be undone
+func softDeletePartner(p *Partner, actorID string) error {
+	now := time.Now().UTC()
+	p.DeletedAt = &now
+	p.DeletedBy = actorID
+	if err := savePartners(); err != nil {
+		p.DeletedAt, p.DeletedBy = nil, ""
+		return err
+	}
+	recordPartnerRevision(partnerActionDelete, actorID, *p)
+	return nil
+}
+
+// Undo a soft delete
+func restoreDeletedPartner(p *Partner, actorID string) error {
+	deletedAt, deletedBy := p.DeletedAt, p.DeletedBy
+	p.DeletedAt, p.DeletedBy = nil, ""
+	if err := savePartners(); err != nil {
+		p.DeletedAt, p.DeletedBy = deletedAt, deletedBy
+		return err
+	}
+	recordPartnerRevision(partnerActionRestore, actorID, *p)
+	return nil
+}
+
+// Handle the "Undo" button shown after /delpartner
+func handlePartnerUndoButton(s *discordgo.Session, i *discordgo.InteractionCreate) {
+	if !hasRole(i.Member.Roles, addPartnerRoleID) {
+		respondEphemeral(s, i, "You do not have permission to use this command.")
+		return
+	}
+	name := strings.TrimPrefix(i.MessageComponentData().CustomID, partnerUndoCustomPrefix)
+	update := func(content string) {
+		s.InteractionRespond(i.Interaction, &discordgo.InteractionResponse{
+			Type: discordgo.InteractionResponseUpdateMessage,
+			Data: &discordgo.InteractionResponseData{
+				Content:    content,
+				Components: []discordgo.MessageComponent{},
+			},
+		})
+	}
+	partnersMu.Lock()
+	if findPartner(name) != nil {
+		partnersMu.Unlock()
+		update("A partner named " + name + " already exists, so the delete can't be undone.")
+		return
+	}
+	p := findDeletedPartner(name)
+	if p == nil {
+		partnersMu.Unlock()
+		update("That partner can no longer be restored from here. Use /partnerhistory instead.")
+		return
+	}
+	if err := restoreDeletedPartner(p, i.Member.User.ID); err != nil {
+		partnersMu.Unlock()
+		log.Printf("Error restoring partner: %v", err)
+		update("Failed to restore the partner: " + err.Error())
+		return
+	}
+	restoredName := p.Name
+	partnersMu.Unlock()
+	update(restoredName + " has been restored.")
+	refreshPartnersPanel(s)
+}
+
+// Handle /partnerhistory: list revisions for a partner or restore one
+func handlePartnerHistoryCommand(s *discordgo.Session, i *discordgo.InteractionCreate) {
+	if !hasRole(i.Member.Roles, addPartnerRoleID) {
+		respondEphemeral(s, i, "You do not have permission to use this command.")
+		return
+	}
+	opts := optionMap(i.ApplicationCommandData().Options)
+	name := strings.TrimSpace(opts["name"].StringValue())
+	revisions := partnerRevisions(name)
+	if len(revisions) == 0 {
+		respondEphemeral(s, i, "No history found for that partner.")
+		return
+	}
+
+	if opt, ok := opts["restore"]; ok {
+		id := int(opt.IntValue())
+		var rev *PartnerRevision
+		for idx := range revisions {
+			if revisions[idx].ID == id {
+				rev = &revisions[idx]
+				break
+			}
+		}
+		if rev == nil {
+			respondEphemeral(s, i, fmt.Sprintf("Revision #%d doesn't belong to %s.", id, name))
+			return
+		}
+		restored := rev.Snapshot
+		restored.DeletedAt, restored.DeletedBy = nil, ""
+		if errs := validatePartner(s, &restored); len(errs) > 0 {
+			respondEphemeral(s, i, formatPartnerErrors(errs))
+			return
+		}
+		partnersMu.Lock()
+		previous := append([]Partner(nil), partners...)
+		if p := findPartner(name); p != nil {
+			*p = restored
+		} else {
+			purgeDeletedPartner(name)
+			partners = append(partners, restored)
+		}
+		if err := savePartners(); err != nil {
+			partners = previous
+			partnersMu.Unlock()
+			respondEphemeral(s, i, "Failed to save partners: "+err.Error())
+			return
+		}
+		partnersMu.Unlock()
+		recordPartnerRevision(partnerActionRestore, i.Member.User.ID, restored)
+		respondEphemeral(s, i, fmt.Sprintf("%s restored to revision #%d.", restored.Name, id))
+		refreshPartnersPanel(s)
+		return
+	}
+
+	var lines []string
+	for idx, rev := range revisions {
+		if idx == partnerHistoryPageSize {
+			lines = append(lines, fmt.Sprintf("…and %d older revision(s).", len(revisions)-idx))
+			break
+		}
+		lines = append(lines, fmt.Sprintf("**#%d** %s by <@%s> <t:%d:R>", rev.ID, rev.Action, rev.Actor, rev.Time.Unix()))
+	}
+	embed := &discordgo.MessageEmbed{
+		Title:       "History: " + revisions[0].Partner,
+		Description: strings.Join(lines, "\n"),
+		Footer:      &discordgo.MessageEmbedFooter{Text: "Use /partnerhistory with restore:<number> to roll back to a revision."},
+	}
+	s.InteractionRespond(i.Interaction, &discordgo.InteractionResponse{
+		Type: discordgo.InteractionResponseChannelMessageWithSource,
+		Data: &discordgo.InteractionResponseData{
+			Embeds:          []*discordgo.MessageEmbed{embed},
+			Flags:           discordgo.MessageFlagsEphemeral,
+			AllowedMentions: &discordgo.MessageAllowedMentions{},
+		},
+	})
+}
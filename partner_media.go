@@ -0,0 +1,263 @@
+package main
+
+import (
+	"fmt"
+	"log"
+	"strconv"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/bwmarrin/discordgo"
+)
+
+// --- Partner Links and Media Section ---
+
+// A named link rendered as a link button on the partner's offer card
+type PartnerLink struct {
+	Label string `json:"label"`
+	URL   string `json:"url"`
+	Emoji string `json:"emoji,omitempty"`
+}
+
+const (
+	maxPartnerExtraLinks    = 4 // Plus the main offering link fills one row of five buttons
+	maxButtonLabelLength    = 80
+	maxEmbedFooterLength    = 2048
+	defaultOfferingLinkText = "Access Offering"
+)
+
+// All of a partner's links with the main offering link first
+func (p *Partner) allLinks() []PartnerLink {
+	links := []PartnerLink{{Label: defaultOfferingLinkText, URL: p.Link}}
+	return append(links, p.Links...)
+}
+
+// Parse "Label|URL|emoji; Label|URL" into links. "none" clears the list.
+func parsePartnerLinks(input string) ([]PartnerLink, error) {
+	input = strings.TrimSpace(input)
+	if strings.EqualFold(input, "none") {
+		return nil, nil
+	}
+	var links []PartnerLink
+	for _, entry := range strings.Split(input, ";") {
+		entry = strings.TrimSpace(entry)
+		if entry == "" {
+			continue
+		}
+		parts := strings.Split(entry, "|")
+		if len(parts) < 2 || len(parts) > 3 {
+			return nil, fmt.Errorf("%q should look like Label|https://example.com", entry)
+		}
+		link := PartnerLink{Label: strings.TrimSpace(parts[0]), URL: strings.TrimSpace(parts[1])}
+		if len(parts) == 3 {
+			link.Emoji = strings.TrimSpace(parts[2])
+		}
+		links = append(links, link)
+	}
+	return links, nil
+}
+
+// Format links back into the form accepted by parsePartnerLinks
+func formatPartnerLinks(links []PartnerLink) string {
+	var parts []string
+	for _, l := range links {
+		entry := l.Label + "|" + l.URL
+		if l.Emoji != "" {
+			entry += "|" + l.Emoji
+		}
+		parts = append(parts, entry)
+	}
+	return strings.Join(parts, "; ")
+}
+
+// Parse a hex colour like #5865F2 into an embed colour
+func parsePartnerColor(input string) (int, error) {
+	v, err := strconv.ParseUint(strings.TrimPrefix(strings.TrimSpace(input), "#"), 16, 32)
+	if err != nil || v > 0xFFFFFF {
+		return 0, fmt.Errorf("must be a hex colour like #5865F2")
+	}
+	return int(v), nil
+}
+
+// Apply the banner, colour, footer and links options shared by /addpartner and /editpartner.
+// "none" clears an optional field.
+func applyPartnerMediaOptions(p *Partner, opts map[string]*discordgo.ApplicationCommandInteractionDataOption) error {
+	for name, field := range map[string]*string{"banner": &p.BannerURL, "color": &p.Color, "footer": &p.Footer} {
+		if opt, ok := opts[name]; ok {
+			*field = strings.TrimSpace(opt.StringValue())
+			if strings.EqualFold(*field, "none") {
+				*field = ""
+			}
+		}
+	}
+	if opt, ok := opts["links"]; ok {
+		links, err := parsePartnerLinks(opt.StringValue())
+		if err != nil {
+			return fmt.Errorf("links: %v", err)
+		}
+		p.Links = links
+	}
+	return nil
+}
+
+// Validate the optional media fields and extra links
+func validatePartnerMedia(s *discordgo.Session, p *Partner) []partnerFieldError {
+	var errs []partnerFieldError
+	if p.BannerURL != "" {
+		if problem := validateHTTPURL(p.BannerURL); problem != "" {
+			errs = append(errs, partnerFieldError{"banner", problem})
+		}
+	}
+	if p.Color != "" {
+		if _, err := parsePartnerColor(p.Color); err != nil {
+			errs = append(errs, partnerFieldError{"color", err.Error()})
+		}
+	}
+	if n := utf8.RuneCountInString(p.Footer); n > maxEmbedFooterLength {
+		errs = append(errs, partnerFieldError{"footer", fmt.Sprintf("is %d characters; the limit is %d", n, maxEmbedFooterLength)})
+	}
+	if len(p.Links) > maxPartnerExtraLinks {
+		errs = append(errs, partnerFieldError{"links", fmt.Sprintf("has %d links; the limit is %d", len(p.Links), maxPartnerExtraLinks)})
+	}
+	for _, l := range p.Links {
+		if n := utf8.RuneCountInString(l.Label); n == 0 || n > maxButtonLabelLength {
+			errs = append(errs, partnerFieldError{"links", fmt.Sprintf("label %q must be 1 to %d characters", l.Label, maxButtonLabelLength)})
+		}
+		if problem := validateHTTPURL(l.URL); problem != "" {
+			errs = append(errs, partnerFieldError{"links", fmt.Sprintf("%s URL %s", l.Label, problem)})
+		}
+		if l.Emoji != "" {
+			if problem := validatePartnerEmoji(s, l.Emoji); problem != "" {
+				errs = append(errs, partnerFieldError{"links", fmt.Sprintf("%s emoji %s", l.Label, problem)})
+			}
+		}
+	}
+	return errs
+}
+
+// Apply banner, colour and footer to a partner embed
+func decoratePartnerEmbed(embed *discordgo.MessageEmbed, p *Partner) {
+	if p.BannerURL != "" {
+		embed.Image = &discordgo.MessageEmbedImage{URL: p.BannerURL}
+	}
+	if color, err := parsePartnerColor(p.Color); err == nil && p.Color != "" {
+		embed.Color = color
+	}
+	if p.Footer != "" {
+		embed.Footer = &discordgo.MessageEmbedFooter{Text: p.Footer}
+	}
+}
+
+// Link buttons for an eligible member, using signed redirect links when enabled
+func partnerLinkButtons(p *Partner, userID string, roles []string) discordgo.ActionsRow {
+	var buttons []discordgo.MessageComponent
+	for _, l := range p.allLinks() {
+		button := discordgo.Button{
+			Label: l.Label,
+			Style: discordgo.LinkButton,
+			URL:   partnerLinkFor(p, l, userID, roles),
+		}
+		if l.Emoji != "" {
+			name, id, animated := parseEmoji(l.Emoji)
+			button.Emoji = &discordgo.ComponentEmoji{Name: name, ID: id, Animated: animated}
+		}
+		buttons = append(buttons, button)
+	}
+	return discordgo.ActionsRow{Components: buttons}
+}
+
+// Handle /editpartner: change any of a partner's public fields
+func handleEditPartnerCommand(s *discordgo.Session, i *discordgo.InteractionCreate) {
+	if !hasRole(i.Member.Roles, addPartnerRoleID) {
+		respondEphemeral(s, i, "You do not have permission to use this command.")
+		return
+	}
+	opts := optionMap(i.ApplicationCommandData().Options)
+	partnersMu.RLock()
+	var original Partner
+	p := findPartner(opts["name"].StringValue())
+	if p != nil {
+		original = *p
+	}
+	partnersMu.RUnlock()
+	if p == nil {
+		respondEphemeral(s, i, "Partner not found.")
+		return
+	}
+	updated := original
+	for name, field := range map[string]*string{
+		"description": &updated.Description,
+		"offering":    &updated.Offering,
+		"logo":        &updated.LogoURL,
+		"link":        &updated.Link,
+		"emoji":       &updated.Emoji,
+	} {
+		if opt, ok := opts[name]; ok {
+			*field = opt.StringValue()
+		}
+	}
+	if opt, ok := opts["tags"]; ok {
+		updated.Tags = parseTags(opt.StringValue())
+	}
+	// A new logo URL replaces a previously uploaded logo file
+	_, newLogoFile := opts["logo_file"]
+	if _, ok := opts["logo"]; ok && !newLogoFile {
+		updated.LogoFile = ""
+	}
+	if err := applyPartnerMediaOptions(&updated, opts); err != nil {
+		respondEphemeral(s, i, err.Error())
+		return
+	}
+	logo, err := parsePartnerLogoOptions(i, &updated, opts)
+	if err != nil {
+		respondEphemeral(s, i, err.Error())
+		return
+	}
+	if logo == nil && partnersEqual(original, updated) {
+		respondEphemeral(s, i, "Nothing to change.")
+		return
+	}
+	if errs := logo.filterErrors(validatePartner(s, &updated)); len(errs) > 0 {
+		respondEphemeral(s, i, formatPartnerErrors(errs))
+		return
+	}
+
+	// Downloading the logo and uploading its emoji can outlast the interaction deadline
+	s.InteractionRespond(i.Interaction, &discordgo.InteractionResponse{
+		Type: discordgo.InteractionResponseDeferredChannelMessageWithSource,
+		Data: &discordgo.InteractionResponseData{Flags: discordgo.MessageFlagsEphemeral},
+	})
+	reply := func(content string) {
+		s.InteractionResponseEdit(i.Interaction, &discordgo.WebhookEdit{Content: &content})
+	}
+	if err := logo.prepare(s, &updated); err != nil {
+		reply(err.Error())
+		return
+	}
+
+	partnersMu.Lock()
+	p = findPartner(original.Name)
+	if p == nil || !partnersEqual(*p, original) {
+		partnersMu.Unlock()
+		logo.discard(s)
+		reply(original.Name + " was changed by someone else in the meantime; run /editpartner again.")
+		return
+	}
+	*p = updated
+	if err := savePartners(); err != nil {
+		*p = original
+		partnersMu.Unlock()
+		logo.discard(s)
+		reply("Failed to save partners: " + err.Error())
+		return
+	}
+	content := updated.Name + " updated."
+	if err := logo.commit(); err != nil {
+		log.Printf("Error storing partner logo for %s: %v", updated.Name, err)
+		content += " The uploaded logo couldn't be stored: " + err.Error()
+	}
+	partnersMu.Unlock()
+	recordPartnerRevision(partnerActionUpdate, i.Member.User.ID, updated)
+	reply(content)
+	refreshPartnersPanel(s)
+}
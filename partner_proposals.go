@@ -0,0 +1,335 @@
+package main
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"log"
+	"os"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/bwmarrin/discordgo"
+)
+
+// --- Partner Proposal Section ---
+
+// Proposal review states
+const (
+	proposalPending          = "pending"
+	proposalApproved         = "approved"
+	proposalRejected         = "rejected"
+	proposalChangesRequested = "changes_requested"
+)
+
+// A partner suggested by a community manager, waiting for staff review
+type PartnerProposal struct {
+	ID              string    `json:"id"`
+	Partner         Partner   `json:"partner"`
+	ProposerID      string    `json:"proposer_id"`
+	Status          string    `json:"status"`
+	ReviewerID      string    `json:"reviewer_id,omitempty"`
+	Note            string    `json:"note,omitempty"`
+	ReviewMessageID string    `json:"review_message_id,omitempty"`
+	CreatedAt       time.Time `json:"created_at"`
+	UpdatedAt       time.Time `json:"updated_at"`
+}
+
+const partnerProposalsFile = "partner_proposals.json"
+
+var (
+	partnerProposals   []PartnerProposal
+	partnerProposalsMu sync.Mutex
+)
+
+// Load partner proposals from file
+func loadPartnerProposals() error {
+	b, err := os.ReadFile(partnerProposalsFile)
+	if err != nil {
+		if os.IsNotExist(err) {
+			partnerProposals = []PartnerProposal{}
+			return nil
+		}
+		return err
+	}
+	return json.Unmarshal(b, &partnerProposals)
+}
+
+// Save partner proposals to file. Callers must hold partnerProposalsMu.
+func savePartnerProposals() error {
+	b, err := json.MarshalIndent(partnerProposals, "", "  ")
+	if err != nil {
+		return err
+	}
+	return writeFileAtomic(partnerProposalsFile, b, 0644)
+}
+
+// Find a proposal by ID. Callers must hold partnerProposalsMu.
+func findProposal(id string) *PartnerProposal {
+	for idx := range partnerProposals {
+		if partnerProposals[idx].ID == id {
+			return &partnerProposals[idx]
+		}
+	}
+	return nil
+}
+
+// Build the review embed for a proposal
+func proposalEmbed(pr *PartnerProposal) *discordgo.MessageEmbed {
+	p := pr.Partner
+	status := map[string]string{
+		proposalPending:          "🕒 Pending review",
+		proposalApproved:         "✅ Approved",
+		proposalRejected:         "❌ Rejected",
+		proposalChangesRequested: "✏️ Changes requested",
+	}[pr.Status]
+	embed := &discordgo.MessageEmbed{
+		Title:       "Partner Proposal: " + p.Name,
+		Description: truncateRunes(p.Description, 2000),
+		Thumbnail:   &discordgo.MessageEmbedThumbnail{URL: p.LogoURL},
+		Fields: []*discordgo.MessageEmbedField{
+			{Name: "Offering", Value: truncateRunes(p.Offering, maxEmbedFieldValueLength)},
+			{Name: "Link", Value: p.Link, Inline: true},
+			{Name: "Emoji", Value: p.Emoji, Inline: true},
+			{Name: "Proposed by", Value: "<@" + pr.ProposerID + ">", Inline: true},
+			{Name: "Status", Value: status},
+		},
+		Footer: &discordgo.MessageEmbedFooter{Text: "Proposal " + pr.ID},
+	}
+	if len(p.Tags) > 0 {
+		embed.Fields = append(embed.Fields, &discordgo.MessageEmbedField{Name: "Tags", Value: strings.Join(p.Tags, ", ")})
+	}
+	if pr.ReviewerID != "" {
+		embed.Fields[4].Value += " by <@" + pr.ReviewerID + ">"
+	}
+	if pr.Note != "" {
+		embed.Fields = append(embed.Fields, &discordgo.MessageEmbedField{Name: "Reviewer note", Value: truncateRunes(pr.Note, maxEmbedFieldValueLength)})
+	}
+	return embed
+}
+
+// Review buttons for a pending proposal
+func proposalButtons(id string) []discordgo.MessageComponent {
+	return []discordgo.MessageComponent{
+		discordgo.ActionsRow{Components: []discordgo.MessageComponent{
+			discordgo.Button{Label: "Approve", CustomID: "proposal_approve_" + id, Style: discordgo.SuccessButton},
+			discordgo.Button{Label: "Request Changes", CustomID: "proposal_changes_" + id, Style: discordgo.SecondaryButton},
+			discordgo.Button{Label: "Reject", CustomID: "proposal_reject_" + id, Style: discordgo.DangerButton},
+		}},
+	}
+}
+
+// DM the proposer about a review decision
+func notifyProposer(s *discordgo.Session, pr *PartnerProposal, content string) {
+	dm, err := s.UserChannelCreate(pr.ProposerID)
+	if err != nil {
+		log.Printf("Error creating DM channel: %v", err)
+		return
+	}
+	if _, err := s.ChannelMessageSend(dm.ID, content); err != nil {
+		log.Printf("Error sending DM to proposer: %v", err)
+	}
+}
+
+// Handle /proposepartner: queue a partner for staff approval
+func handleProposePartnerCommand(s *discordgo.Session, i *discordgo.InteractionCreate) {
+	if !hasRole(i.Member.Roles, addPartnerRoleID) && (config.ProposePartnerRoleID == "" || !hasRole(i.Member.Roles, config.ProposePartnerRoleID)) {
+		respondEphemeral(s, i, "You do not have permission to use this command.")
+		return
+	}
+	if config.PartnerReviewChannelID == "" {
+		respondEphemeral(s, i, "Partner proposals aren't set up yet. Ask an admin to configure a review channel.")
+		return
+	}
+	opts := optionMap(i.ApplicationCommandData().Options)
+	p := Partner{
+		Name:        strings.TrimSpace(opts["name"].StringValue()),
+		Description: opts["description"].StringValue(),
+		Offering:    opts["offering"].StringValue(),
+		LogoURL:     opts["logo"].StringValue(),
+		Link:        opts["link"].StringValue(),
+		Emoji:       opts["emoji"].StringValue(),
+	}
+	if opt, ok := opts["tags"]; ok {
+		p.Tags = parseTags(opt.StringValue())
+	}
+	partnersMu.RLock()
+	exists := findPartner(p.Name) != nil
+	partnersMu.RUnlock()
+	if exists {
+		respondEphemeral(s, i, "A partner with that name already exists. Please choose a unique name.")
+		return
+	}
+	if errs := validatePartner(s, &p); len(errs) > 0 {
+		respondEphemeral(s, i, strings.Replace(formatPartnerErrors(errs), "The partner wasn't saved:", "The proposal wasn't submitted:", 1))
+		return
+	}
+
+	partnerProposalsMu.Lock()
+	defer partnerProposalsMu.Unlock()
+	for _, pr := range partnerProposals {
+		if pr.Status == proposalPending && strings.EqualFold(pr.Partner.Name, p.Name) {
+			respondEphemeral(s, i, "A proposal for that partner is already waiting for review.")
+			return
+		}
+	}
+	idBytes := make([]byte, 4)
+	rand.Read(idBytes)
+	now := time.Now().UTC()
+	pr := PartnerProposal{
+		ID:         hex.EncodeToString(idBytes),
+		Partner:    p,
+		ProposerID: i.Member.User.ID,
+		Status:     proposalPending,
+		CreatedAt:  now,
+		UpdatedAt:  now,
+	}
+	msg, err := s.ChannelMessageSendComplex(config.PartnerReviewChannelID, &discordgo.MessageSend{
+		Embeds:          []*discordgo.MessageEmbed{proposalEmbed(&pr)},
+		Components:      proposalButtons(pr.ID),
+		AllowedMentions: &discordgo.MessageAllowedMentions{},
+	})
+	if err != nil {
+		log.Printf("Error posting partner proposal: %v", err)
+		respondEphemeral(s, i, "Failed to submit the proposal. Please contact an admin.")
+		return
+	}
+	pr.ReviewMessageID = msg.ID
+	partnerProposals = append(partnerProposals, pr)
+	if err := savePartnerProposals(); err != nil {
+		log.Printf("Error saving partner proposals: %v", err)
+	}
+	respondEphemeral(s, i, "Thanks! Your proposal for "+p.Name+" has been sent to staff for review.")
+}
+
+// Handle Approve / Request Changes / Reject buttons on a proposal
+func handleProposalButton(s *discordgo.Session, i *discordgo.InteractionCreate) {
+	if !hasRole(i.Member.Roles, addPartnerRoleID) {
+		respondEphemeral(s, i, "Only partner admins can review proposals.")
+		return
+	}
+	action, id, _ := strings.Cut(strings.TrimPrefix(i.MessageComponentData().CustomID, "proposal_"), "_")
+
+	partnerProposalsMu.Lock()
+	pr := findProposal(id)
+	if pr == nil || pr.Status != proposalPending {
+		partnerProposalsMu.Unlock()
+		respondEphemeral(s, i, "This proposal has already been reviewed.")
+		return
+	}
+	if action != "approve" {
+		partnerProposalsMu.Unlock()
+		title, label := "Reject Proposal", "Reason (sent to the proposer)"
+		if action == "changes" {
+			title, label = "Request Changes", "What should be changed?"
+		}
+		s.InteractionRespond(i.Interaction, &discordgo.InteractionResponse{
+			Type: discordgo.InteractionResponseModal,
+			Data: &discordgo.InteractionResponseData{
+				CustomID: "proposalnote_" + action + "_" + id,
+				Title:    title,
+				Components: []discordgo.MessageComponent{
+					discordgo.ActionsRow{Components: []discordgo.MessageComponent{
+						discordgo.TextInput{
+							CustomID:  "note",
+							Label:     label,
+							Style:     discordgo.TextInputParagraph,
+							Required:  action == "changes",
+							MaxLength: maxEmbedFieldValueLength,
+						},
+					}},
+				},
+			},
+		})
+		return
+	}
+
+	partnersMu.Lock()
+	if findPartner(pr.Partner.Name) != nil {
+		partnersMu.Unlock()
+		partnerProposalsMu.Unlock()
+		respondEphemeral(s, i, "A partner with that name already exists, so this proposal can't be approved as-is.")
+		return
+	}
+	purgeDeletedPartner(pr.Partner.Name)
+	partners = append(partners, pr.Partner)
+	if err := savePartners(); err != nil {
+		partners = partners[:len(partners)-1]
+		partnersMu.Unlock()
+		partnerProposalsMu.Unlock()
+		respondEphemeral(s, i, "Failed to save partners: "+err.Error())
+		return
+	}
+	partnersMu.Unlock()
+	recordPartnerRevision(partnerActionCreate, i.Member.User.ID, pr.Partner)
+	pr.Status = proposalApproved
+	pr.ReviewerID = i.Member.User.ID
+	pr.UpdatedAt = time.Now().UTC()
+	if err := savePartnerProposals(); err != nil {
+		log.Printf("Error saving partner proposals: %v", err)
+	}
+	reviewed := *pr
+	partnerProposalsMu.Unlock()
+
+	s.InteractionRespond(i.Interaction, &discordgo.InteractionResponse{
+		Type: discordgo.InteractionResponseUpdateMessage,
+		Data: &discordgo.InteractionResponseData{
+			Embeds:          []*discordgo.MessageEmbed{proposalEmbed(&reviewed)},
+			Components:      []discordgo.MessageComponent{},
+			AllowedMentions: &discordgo.MessageAllowedMentions{},
+		},
+	})
+	notifyProposer(s, &reviewed, fmt.Sprintf("✅ Your proposal for %s was approved and is now on the partners panel.", reviewed.Partner.Name))
+	refreshPartnersPanel(s)
+}
+
+// Handle the reviewer note modal for rejections and change requests
+func handleProposalNoteModal(s *discordgo.Session, i *discordgo.InteractionCreate) {
+	// The role may have been removed while the modal was open
+	if !hasRole(i.Member.Roles, addPartnerRoleID) {
+		respondEphemeral(s, i, "Only partner admins can review proposals.")
+		return
+	}
+	data := i.ModalSubmitData()
+	action, id, _ := strings.Cut(strings.TrimPrefix(data.CustomID, "proposalnote_"), "_")
+	note := strings.TrimSpace(modalValues(data)["note"])
+
+	partnerProposalsMu.Lock()
+	pr := findProposal(id)
+	if pr == nil || pr.Status != proposalPending {
+		partnerProposalsMu.Unlock()
+		respondEphemeral(s, i, "This proposal has already been reviewed.")
+		return
+	}
+	pr.Status = proposalRejected
+	if action == "changes" {
+		pr.Status = proposalChangesRequested
+	}
+	pr.ReviewerID = i.Member.User.ID
+	pr.Note = note
+	pr.UpdatedAt = time.Now().UTC()
+	if err := savePartnerProposals(); err != nil {
+		log.Printf("Error saving partner proposals: %v", err)
+	}
+	reviewed := *pr
+	partnerProposalsMu.Unlock()
+
+	s.InteractionRespond(i.Interaction, &discordgo.InteractionResponse{
+		Type: discordgo.InteractionResponseUpdateMessage,
+		Data: &discordgo.InteractionResponseData{
+			Embeds:          []*discordgo.MessageEmbed{proposalEmbed(&reviewed)},
+			Components:      []discordgo.MessageComponent{},
+			AllowedMentions: &discordgo.MessageAllowedMentions{},
+		},
+	})
+	dm := fmt.Sprintf("❌ Your proposal for %s was not approved.", reviewed.Partner.Name)
+	if reviewed.Status == proposalChangesRequested {
+		dm = fmt.Sprintf("✏️ Staff asked for changes to your proposal for %s. Update it and submit again with /proposepartner.", reviewed.Partner.Name)
+	}
+	if note != "" {
+		dm += "\n\n**Note:** " + note
+	}
+	notifyProposer(s, &reviewed, dm)
+}
@@ -0,0 +1,220 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"log"
+	"os"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/bwmarrin/discordgo"
+)
+
+// --- Partner Scheduling Section ---
+
+const (
+	partnerRemindersFile             = "partner_reminders.json"
+	defaultPartnerExpiryReminderDays = 7
+	partnerSchedulerInterval         = time.Minute
+)
+
+// Reminders already sent, keyed so each deal end date is only reminded once
+var (
+	partnerReminders   = map[string]time.Time{}
+	partnerRemindersMu sync.Mutex
+)
+
+// Accepted formats for partner dates, all interpreted as UTC
+var partnerTimeLayouts = []string{time.RFC3339, "2006-01-02 15:04", "2006-01-02"}
+
+// Parse a partner date option
+func parsePartnerTime(input string) (time.Time, error) {
+	input = strings.TrimSpace(input)
+	for _, layout := range partnerTimeLayouts {
+		if t, err := time.ParseInLocation(layout, input, time.UTC); err == nil {
+			return t.UTC(), nil
+		}
+	}
+	return time.Time{}, fmt.Errorf("could not understand the date %q; use YYYY-MM-DD or YYYY-MM-DD HH:MM (UTC)", input)
+}
+
+// Apply starts_at/ends_at slash command options to a partner
+func applyPartnerScheduleOptions(p *Partner, opts map[string]*discordgo.ApplicationCommandInteractionDataOption) error {
+	if opt, ok := opts["starts_at"]; ok {
+		t, err := parsePartnerTime(opt.StringValue())
+		if err != nil {
+			return fmt.Errorf("starts_at: %v", err)
+		}
+		p.StartsAt = &t
+	}
+	if opt, ok := opts["ends_at"]; ok {
+		t, err := parsePartnerTime(opt.StringValue())
+		if err != nil {
+			return fmt.Errorf("ends_at: %v", err)
+		}
+		p.EndsAt = &t
+	}
+	if p.StartsAt != nil && p.EndsAt != nil && !p.EndsAt.After(*p.StartsAt) {
+		return fmt.Errorf("ends_at must be after starts_at")
+	}
+	return nil
+}
+
+// Describe a partner's schedule for admin replies, or "" when it has none
+func describePartnerSchedule(p *Partner) string {
+	var parts []string
+	if p.StartsAt != nil {
+		parts = append(parts, fmt.Sprintf("shown from <t:%d:f>", p.StartsAt.Unix()))
+	}
+	if p.EndsAt != nil {
+		parts = append(parts, fmt.Sprintf("hidden from <t:%d:f>", p.EndsAt.Unix()))
+	}
+	if len(parts) == 0 {
+		return ""
+	}
+	return " Scheduled: " + strings.Join(parts, ", ") + "."
+}
+
+// Handle /partnerschedule: change the deal window of an existing partner
+func handlePartnerScheduleCommand(s *discordgo.Session, i *discordgo.InteractionCreate) {
+	if !hasRole(i.Member.Roles, addPartnerRoleID) {
+		respondEphemeral(s, i, "You do not have permission to use this command.")
+		return
+	}
+	opts := optionMap(i.ApplicationCommandData().Options)
+	partnersMu.Lock()
+	p := findPartner(opts["name"].StringValue())
+	if p == nil {
+		partnersMu.Unlock()
+		respondEphemeral(s, i, "Partner not found.")
+		return
+	}
+	updated := *p
+	if opt, ok := opts["clear"]; ok && opt.BoolValue() {
+		updated.StartsAt, updated.EndsAt = nil, nil
+	}
+	if err := applyPartnerScheduleOptions(&updated, opts); err != nil {
+		partnersMu.Unlock()
+		respondEphemeral(s, i, err.Error())
+		return
+	}
+	original := *p
+	*p = updated
+	if err := savePartners(); err != nil {
+		*p = original
+		partnersMu.Unlock()
+		respondEphemeral(s, i, "Failed to save partners: "+err.Error())
+		return
+	}
+	partnersMu.Unlock()
+	recordPartnerRevision(partnerActionUpdate, i.Member.User.ID, updated)
+	summary := describePartnerSchedule(&updated)
+	if summary == "" {
+		summary = " No dates set; the partner is always shown."
+	}
+	respondEphemeral(s, i, "Schedule for "+updated.Name+" updated."+summary)
+	refreshPartnersPanel(s)
+}
+
+// Load sent reminders from file
+func loadPartnerReminders() error {
+	b, err := os.ReadFile(partnerRemindersFile)
+	if err != nil {
+		if os.IsNotExist(err) {
+			partnerReminders = map[string]time.Time{}
+			return nil
+		}
+		return err
+	}
+	return json.Unmarshal(b, &partnerReminders)
+}
+
+// Save sent reminders to file. Callers must hold partnerRemindersMu.
+func savePartnerReminders() error {
+	b, err := json.MarshalIndent(partnerReminders, "", "  ")
+	if err != nil {
+		return err
+	}
+	return writeFileAtomic(partnerRemindersFile, b, 0644)
+}
+
+// Send an admin reminder once per key, remembering it across restarts.
+// A reminder that couldn't be sent is tried again on the next pass.
+func sendReminderOnce(s *discordgo.Session, key, content string) {
+	partnerRemindersMu.Lock()
+	defer partnerRemindersMu.Unlock()
+	if _, sent := partnerReminders[key]; sent {
+		return
+	}
+	if err := sendAdminAlert(s, content); err != nil {
+		return
+	}
+	partnerReminders[key] = time.Now().UTC()
+	if err := savePartnerReminders(); err != nil {
+		log.Printf("Error saving partner reminders: %v", err)
+	}
+}
+
+// Days of notice admins get before a deal ends
+func partnerExpiryReminderDays() int {
+	if config.PartnerExpiryReminderDays > 0 {
+		return config.PartnerExpiryReminderDays
+	}
+	return defaultPartnerExpiryReminderDays
+}
+
+// Snapshot of which partners are live, used to detect panel changes. Callers must hold partnersMu.
+func livePartnersKey(now time.Time) string {
+	var names []string
+	for _, p := range partners {
+		if p.isLive(now) {
+			names = append(names, p.Name)
+		}
+	}
+	return strings.Join(names, "\x00")
+}
+
+// Run one pass of the partner schedule: refresh the panel when partners start or
+// end, and remind admins about deals that are about to expire. partnersMu is only
+// held while reading partners, never across Discord calls.
+func runPartnerSchedule(s *discordgo.Session, now time.Time, lastKey *string) {
+	publishPendingAnnouncements(s, now)
+
+	partnersMu.RLock()
+	key := livePartnersKey(now)
+	current := currentPartners()
+	partnersMu.RUnlock()
+	if key != *lastKey {
+		*lastKey = key
+		log.Println("Partner schedule changed, refreshing partners panel...")
+		refreshPartnersPanel(s)
+	}
+	sendRenewalReminders(s, current, now)
+	runPartnerSpotlight(s, now)
+
+	notice := time.Duration(partnerExpiryReminderDays()) * 24 * time.Hour
+	for _, p := range current {
+		if p.EndsAt == nil || !p.EndsAt.After(now) || p.EndsAt.Sub(now) > notice {
+			continue
+		}
+		key := "expiry:" + p.Name + ":" + p.EndsAt.Format(time.RFC3339)
+		sendReminderOnce(s, key, fmt.Sprintf("⏰ The %s partnership ends <t:%d:R> (<t:%d:f>). Renew it with /partnerschedule if the deal continues.",
+			p.Name, p.EndsAt.Unix(), p.EndsAt.Unix()))
+	}
+}
+
+// Start the background partner scheduler
+func startPartnerScheduler(s *discordgo.Session) {
+	partnersMu.RLock()
+	lastKey := livePartnersKey(time.Now())
+	partnersMu.RUnlock()
+	go func() {
+		ticker := time.NewTicker(partnerSchedulerInterval)
+		defer ticker.Stop()
+		for now := range ticker.C {
+			runPartnerSchedule(s, now, &lastKey)
+		}
+	}()
+}
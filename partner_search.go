@@ -0,0 +1,182 @@
+package main
+
+import (
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/bwmarrin/discordgo"
+)
+
+// --- Partner Lookup Section ---
+
+const (
+	maxAutocompleteChoices = 25
+	minPartnerMatchScore   = 30
+)
+
+// Levenshtein distance between two short strings
+func editDistance(a, b string) int {
+	ra, rb := []rune(a), []rune(b)
+	prev := make([]int, len(rb)+1)
+	cur := make([]int, len(rb)+1)
+	for j := range prev {
+		prev[j] = j
+	}
+	for i := 1; i <= len(ra); i++ {
+		cur[0] = i
+		for j := 1; j <= len(rb); j++ {
+			cost := 1
+			if ra[i-1] == rb[j-1] {
+				cost = 0
+			}
+			cur[j] = min(prev[j]+1, cur[j-1]+1, prev[j-1]+cost)
+		}
+		prev, cur = cur, prev
+	}
+	return prev[len(rb)]
+}
+
+// Report whether every rune of query appears in s in order
+func isSubsequence(query, s string) bool {
+	qr := []rune(query)
+	if len(qr) == 0 {
+		return true
+	}
+	for _, r := range s {
+		if r == qr[0] {
+			qr = qr[1:]
+			if len(qr) == 0 {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// Score how well a query matches a single word, allowing small typos
+func scoreWord(query, word string) int {
+	switch {
+	case word == query:
+		return 100
+	case strings.HasPrefix(word, query):
+		return 85
+	case strings.Contains(word, query):
+		return 70
+	case len([]rune(query)) >= 4 && editDistance(query, word) <= 1:
+		return 60
+	case len([]rune(query)) >= 6 && editDistance(query, word) <= 2:
+		return 50
+	}
+	return 0
+}
+
+// Score a partner against a search query using its name, tags and description.
+// Higher is better; 0 means no match.
+func scorePartner(p *Partner, query string) int {
+	query = strings.ToLower(strings.TrimSpace(query))
+	if query == "" {
+		return 1
+	}
+	name := strings.ToLower(p.Name)
+	best := 0
+	if s := scoreWord(query, name); s > 0 {
+		best = s + 20
+	}
+	for _, w := range strings.Fields(name) {
+		best = max(best, scoreWord(query, w)+10)
+	}
+	if best == 0 && isSubsequence(query, name) {
+		best = 45
+	}
+	for _, tag := range p.Tags {
+		best = max(best, scoreWord(query, strings.ToLower(tag)))
+	}
+	if strings.Contains(strings.ToLower(p.Description), query) {
+		best = max(best, 35)
+	}
+	for _, w := range strings.Fields(strings.ToLower(p.Description)) {
+		if len([]rune(query)) >= 4 && editDistance(query, strings.Trim(w, ".,;:!?()\"'")) <= 1 {
+			best = max(best, 30)
+			break
+		}
+	}
+	return best
+}
+
+// Live partners matching a query, best match first. Callers must hold partnersMu.
+func searchPartners(query string) []*Partner {
+	type match struct {
+		p     *Partner
+		score int
+	}
+	now := time.Now()
+	var matches []match
+	for idx := range partners {
+		p := &partners[idx]
+		if !p.isLive(now) {
+			continue
+		}
+		if score := scorePartner(p, query); score >= minPartnerMatchScore || strings.TrimSpace(query) == "" {
+			matches = append(matches, match{p, score})
+		}
+	}
+	sort.SliceStable(matches, func(a, b int) bool {
+		if matches[a].score != matches[b].score {
+			return matches[a].score > matches[b].score
+		}
+		return strings.ToLower(matches[a].p.Name) < strings.ToLower(matches[b].p.Name)
+	})
+	out := make([]*Partner, 0, len(matches))
+	for _, m := range matches {
+		out = append(out, m.p)
+	}
+	return out
+}
+
+// Handle autocomplete for /partner
+func handlePartnerAutocomplete(s *discordgo.Session, i *discordgo.InteractionCreate) {
+	var query string
+	for _, opt := range i.ApplicationCommandData().Options {
+		if opt.Name == "name" && opt.Focused {
+			query = opt.StringValue()
+		}
+	}
+	choices := []*discordgo.ApplicationCommandOptionChoice{}
+	partnersMu.RLock()
+	for _, p := range searchPartners(query) {
+		if len(choices) == maxAutocompleteChoices {
+			break
+		}
+		choices = append(choices, &discordgo.ApplicationCommandOptionChoice{Name: p.Name, Value: p.Name})
+	}
+	partnersMu.RUnlock()
+	s.InteractionRespond(i.Interaction, &discordgo.InteractionResponse{
+		Type: discordgo.InteractionApplicationCommandAutocompleteResult,
+		Data: &discordgo.InteractionResponseData{Choices: choices},
+	})
+}
+
+// Handle /partner: show a partner's offering from any channel or DMs
+func handlePartnerLookupCommand(s *discordgo.Session, i *discordgo.InteractionCreate) {
+	query := optionMap(i.ApplicationCommandData().Options)["name"].StringValue()
+	partnersMu.RLock()
+	p := findPartner(query)
+	if p == nil || !p.isLive(time.Now()) {
+		// Fall back to the best fuzzy match when the name wasn't picked from autocomplete
+		if results := searchPartners(query); len(results) > 0 {
+			p = results[0]
+		}
+	}
+	// Copy the partner so the lock isn't held while responding
+	var match Partner
+	if p != nil {
+		match = *p
+	}
+	partnersMu.RUnlock()
+	if p == nil {
+		respondEphemeral(s, i, "No partner matches that name. Start typing to see suggestions.")
+		return
+	}
+	respondWithPartnerView(s, i, &match)
+}
@@ -0,0 +1,274 @@
+package main
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"os"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/bwmarrin/discordgo"
+)
+
+// --- Partner Spotlight Section ---
+
+// Rotation state for spotlight posts
+type spotlightState struct {
+	LastPosted map[string]time.Time `json:"last_posted"`
+	Pinned     string               `json:"pinned,omitempty"`
+	NextRun    time.Time            `json:"next_run"`
+}
+
+const (
+	spotlightFile            = "spotlight.json"
+	defaultSpotlightSchedule = "0 10 * * 1" // Mondays at 10:00 UTC
+)
+
+var (
+	spotlight   = spotlightState{LastPosted: map[string]time.Time{}}
+	spotlightMu sync.Mutex
+
+	errNoSpotlightPartners = errors.New("there are no live partners to spotlight")
+)
+
+// Load spotlight state from file
+func loadSpotlight() error {
+	b, err := os.ReadFile(spotlightFile)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil
+		}
+		return err
+	}
+	if err := json.Unmarshal(b, &spotlight); err != nil {
+		return err
+	}
+	if spotlight.LastPosted == nil {
+		spotlight.LastPosted = map[string]time.Time{}
+	}
+	return nil
+}
+
+// Save spotlight state to file. Callers must hold spotlightMu.
+func saveSpotlight() error {
+	b, err := json.MarshalIndent(spotlight, "", "  ")
+	if err != nil {
+		return err
+	}
+	return writeFileAtomic(spotlightFile, b, 0644)
+}
+
+// The configured spotlight schedule
+func spotlightSchedule() (*cronSchedule, error) {
+	expr := config.SpotlightSchedule
+	if expr == "" {
+		expr = defaultSpotlightSchedule
+	}
+	return parseCronSchedule(expr)
+}
+
+// Pick the next partner to spotlight: the pinned partner if it's live, otherwise
+// the live partner that has gone longest without one. Takes partnersMu and
+// spotlightMu itself and returns a copy, so callers must hold neither.
+func nextSpotlightPartner(now time.Time) (Partner, bool) {
+	partnersMu.RLock()
+	defer partnersMu.RUnlock()
+	spotlightMu.Lock()
+	defer spotlightMu.Unlock()
+	if spotlight.Pinned != "" {
+		if p := findPartner(spotlight.Pinned); p != nil && p.isLive(now) {
+			return *p, true
+		}
+	}
+	var pick *Partner
+	for idx := range partners {
+		p := &partners[idx]
+		if !p.isLive(now) {
+			continue
+		}
+		if pick == nil || spotlight.LastPosted[p.Name].Before(spotlight.LastPosted[pick.Name]) {
+			pick = p
+		}
+	}
+	if pick == nil {
+		return Partner{}, false
+	}
+	return *pick, true
+}
+
+// Post a spotlight embed for a partner
+func postPartnerSpotlight(s *discordgo.Session, p *Partner) error {
+	thumbnail, logoFile := partnerThumbnail(p)
+	embed := &discordgo.MessageEmbed{
+		Title:       "🌟 Partner Spotlight: " + p.Name,
+		Description: fmt.Sprintf("%s\n\n**Offering:** %s", p.Description, p.Offering),
+		Thumbnail:   thumbnail,
+	}
+	decoratePartnerEmbed(embed, p)
+	name, id, animated := parseEmoji(p.Emoji)
+	msg := &discordgo.MessageSend{
+		Embeds: []*discordgo.MessageEmbed{embed},
+		Components: []discordgo.MessageComponent{
+			discordgo.ActionsRow{Components: []discordgo.MessageComponent{
+				discordgo.Button{
+					Label:    "View Offering",
+					Emoji:    &discordgo.ComponentEmoji{Name: name, ID: id, Animated: animated},
+					CustomID: "partner_" + p.Name,
+					Style:    discordgo.PrimaryButton,
+				},
+			}},
+		},
+	}
+	if logoFile != nil {
+		msg.Files = []*discordgo.File{logoFile}
+	}
+	_, err := s.ChannelMessageSendComplex(config.SpotlightChannelID, msg)
+	return err
+}
+
+// Post the next spotlight and advance the rotation, returning the partner's name.
+// No lock is held while posting, so callers must not hold partnersMu or spotlightMu.
+func postNextSpotlight(s *discordgo.Session, now time.Time) (string, error) {
+	p, ok := nextSpotlightPartner(now)
+	if !ok {
+		return "", errNoSpotlightPartners
+	}
+	if err := postPartnerSpotlight(s, &p); err != nil {
+		return "", err
+	}
+	spotlightMu.Lock()
+	defer spotlightMu.Unlock()
+	spotlight.LastPosted[p.Name] = now.UTC()
+	if strings.EqualFold(spotlight.Pinned, p.Name) {
+		spotlight.Pinned = ""
+	}
+	if err := saveSpotlight(); err != nil {
+		log.Printf("Error saving spotlight state: %v", err)
+	}
+	return p.Name, nil
+}
+
+// Post a spotlight when the schedule is due. Called from the partner scheduler.
+func runPartnerSpotlight(s *discordgo.Session, now time.Time) {
+	if config.SpotlightChannelID == "" {
+		return
+	}
+	sched, err := spotlightSchedule()
+	if err != nil {
+		return
+	}
+	spotlightMu.Lock()
+	if spotlight.NextRun.IsZero() {
+		spotlight.NextRun = sched.next(now)
+		if err := saveSpotlight(); err != nil {
+			log.Printf("Error saving spotlight state: %v", err)
+		}
+	}
+	due := !now.Before(spotlight.NextRun)
+	spotlightMu.Unlock()
+	if !due {
+		return
+	}
+	name, err := postNextSpotlight(s, now)
+	switch {
+	case err == nil:
+		log.Printf("Posted partner spotlight for %s", name)
+	case errors.Is(err, errNoSpotlightPartners):
+		log.Printf("Skipping partner spotlight: %v", err)
+	case isPermanentDiscordError(err):
+		// Retrying won't help, so skip this run rather than fail every pass
+		log.Printf("Error posting partner spotlight: %v", err)
+		sendAdminAlert(s, fmt.Sprintf("⚠️ The partner spotlight could not be posted and was skipped: %v", err))
+	default:
+		// Leave NextRun as it is so the next pass tries again
+		log.Printf("Error posting partner spotlight, will retry: %v", err)
+		return
+	}
+	spotlightMu.Lock()
+	defer spotlightMu.Unlock()
+	spotlight.NextRun = sched.next(now)
+	if err := saveSpotlight(); err != nil {
+		log.Printf("Error saving spotlight state: %v", err)
+	}
+}
+
+// Handle /spotlight: show rotation status, pin a partner for next time, or post now
+func handleSpotlightCommand(s *discordgo.Session, i *discordgo.InteractionCreate) {
+	if !hasRole(i.Member.Roles, addPartnerRoleID) {
+		respondEphemeral(s, i, "You do not have permission to use this command.")
+		return
+	}
+	if config.SpotlightChannelID == "" {
+		respondEphemeral(s, i, "Spotlights aren't set up yet. Set spotlightChannelId in config.json.")
+		return
+	}
+	opts := optionMap(i.ApplicationCommandData().Options)
+	now := time.Now()
+
+	pin := ""
+	if opt, ok := opts["pin"]; ok {
+		partnersMu.RLock()
+		var live bool
+		if p := findPartner(opt.StringValue()); p != nil {
+			pin, live = p.Name, p.isLive(now)
+		}
+		partnersMu.RUnlock()
+		if pin == "" {
+			respondEphemeral(s, i, "Partner not found.")
+			return
+		}
+		if !live {
+			respondEphemeral(s, i, pin+" isn't live, so it can't be spotlighted.")
+			return
+		}
+	}
+	spotlightMu.Lock()
+	if pin != "" {
+		spotlight.Pinned = pin
+	}
+	if opt, ok := opts["unpin"]; ok && opt.BoolValue() {
+		spotlight.Pinned = ""
+	}
+	if err := saveSpotlight(); err != nil {
+		log.Printf("Error saving spotlight state: %v", err)
+	}
+	spotlightMu.Unlock()
+
+	reply := ""
+	if opt, ok := opts["post_now"]; ok && opt.BoolValue() {
+		name, err := postNextSpotlight(s, now)
+		if err != nil {
+			respondEphemeral(s, i, "Couldn't post a spotlight: "+err.Error())
+			return
+		}
+		reply = "Posted a spotlight for " + name + ".\n"
+	}
+
+	upNext := "nobody (no live partners)"
+	p, ok := nextSpotlightPartner(now)
+	spotlightMu.Lock()
+	if ok {
+		upNext = p.Name
+		if strings.EqualFold(spotlight.Pinned, p.Name) {
+			upNext += " 📌"
+		}
+	}
+	nextRun := "not scheduled yet"
+	if !spotlight.NextRun.IsZero() {
+		nextRun = fmt.Sprintf("<t:%d:f> (<t:%d:R>)", spotlight.NextRun.Unix(), spotlight.NextRun.Unix())
+	}
+	spotlightMu.Unlock()
+	respondEphemeral(s, i, fmt.Sprintf("%s**Next spotlight:** %s\n**Up next:** %s", reply, nextRun, upNext))
+}
+
+// Validate the spotlight schedule so a typo is reported at startup
+func checkSpotlightConfig() error {
+	if config.SpotlightChannelID == "" {
+		return nil
+	}
+	_, err := spotlightSchedule()
+	return err
+}
@@ -0,0 +1,461 @@
+package main
+
+import (
+	"bytes"
+	"crypto/rand"
+	"encoding/csv"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"log"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/bwmarrin/discordgo"
+)
+
+// --- Partner Import/Export Section ---
+
+const (
+	maxPartnerImportSize = 1 << 20
+	partnerImportTTL     = 15 * time.Minute
+)
+
+// Columns used for CSV import and export
+var partnerCSVHeader = []string{
+	"name", "description", "offering", "logo_url", "link", "emoji",
+	"tags", "starts_at", "ends_at", "eligible_any_of", "eligible_all_of", "eligible_none_of", "not_eligible_message",
+	"links", "banner_url", "color", "footer",
+}
+
+// An import waiting for the admin to confirm the diff preview
+type pendingPartnerImport struct {
+	UserID   string
+	Partners []Partner
+	CSV      bool
+	Expires  time.Time
+}
+
+var (
+	pendingPartnerImports   = map[string]*pendingPartnerImport{}
+	pendingPartnerImportsMu sync.Mutex
+)
+
+// Encode partners as CSV
+func partnersToCSV(list []Partner) ([]byte, error) {
+	formatTime := func(t *time.Time) string {
+		if t == nil {
+			return ""
+		}
+		return t.UTC().Format(time.RFC3339)
+	}
+	var buf bytes.Buffer
+	w := csv.NewWriter(&buf)
+	w.Write(partnerCSVHeader)
+	for _, p := range list {
+		e := p.Eligibility
+		if e == nil {
+			e = &PartnerEligibility{}
+		}
+		w.Write([]string{
+			p.Name, p.Description, p.Offering, p.LogoURL, p.Link, p.Emoji, strings.Join(p.Tags, ", "),
+			formatTime(p.StartsAt), formatTime(p.EndsAt),
+			strings.Join(e.AnyOf, " "), strings.Join(e.AllOf, " "), strings.Join(e.NoneOf, " "), e.NotEligibleMessage,
+			formatPartnerLinks(p.Links), p.BannerURL, p.Color, p.Footer,
+		})
+	}
+	w.Flush()
+	return buf.Bytes(), w.Error()
+}
+
+// Decode partners from CSV written by partnersToCSV
+func partnersFromCSV(data []byte) ([]Partner, error) {
+	records, err := csv.NewReader(bytes.NewReader(data)).ReadAll()
+	if err != nil {
+		return nil, err
+	}
+	if len(records) == 0 {
+		return nil, fmt.Errorf("the file is empty")
+	}
+	cols := map[string]int{}
+	for idx, h := range records[0] {
+		cols[strings.ToLower(strings.TrimSpace(h))] = idx
+	}
+	for _, required := range []string{"name", "description", "offering", "logo_url", "link", "emoji"} {
+		if _, ok := cols[required]; !ok {
+			return nil, fmt.Errorf("missing column %q", required)
+		}
+	}
+	get := func(rec []string, col string) string {
+		if idx, ok := cols[col]; ok && idx < len(rec) {
+			return strings.TrimSpace(rec[idx])
+		}
+		return ""
+	}
+
+	var list []Partner
+	for line, rec := range records[1:] {
+		p := Partner{
+			Name:        get(rec, "name"),
+			Description: get(rec, "description"),
+			Offering:    get(rec, "offering"),
+			LogoURL:     get(rec, "logo_url"),
+			Link:        get(rec, "link"),
+			Emoji:       get(rec, "emoji"),
+			Tags:        parseTags(get(rec, "tags")),
+			BannerURL:   get(rec, "banner_url"),
+			Color:       get(rec, "color"),
+			Footer:      get(rec, "footer"),
+		}
+		if v := get(rec, "links"); v != "" {
+			links, err := parsePartnerLinks(v)
+			if err != nil {
+				return nil, fmt.Errorf("row %d, links: %v", line+2, err)
+			}
+			p.Links = links
+		}
+		for _, col := range []string{"starts_at", "ends_at"} {
+			v := get(rec, col)
+			if v == "" {
+				continue
+			}
+			t, err := parsePartnerTime(v)
+			if err != nil {
+				return nil, fmt.Errorf("row %d, %s: %v", line+2, col, err)
+			}
+			if col == "starts_at" {
+				p.StartsAt = &t
+			} else {
+				p.EndsAt = &t
+			}
+		}
+		e := &PartnerEligibility{
+			AnyOf:              parseIDList(get(rec, "eligible_any_of")),
+			AllOf:              parseIDList(get(rec, "eligible_all_of")),
+			NoneOf:             parseIDList(get(rec, "eligible_none_of")),
+			NotEligibleMessage: get(rec, "not_eligible_message"),
+		}
+		if len(e.AnyOf) > 0 || len(e.AllOf) > 0 || len(e.NoneOf) > 0 || e.NotEligibleMessage != "" {
+			p.Eligibility = e
+		}
+		list = append(list, p)
+	}
+	return list, nil
+}
+
+// CSV has no columns for the uploaded logo, a queued announcement or the admin
+// details, so a CSV row keeps them from the saved partner of the same name.
+// A changed logo_url drops the uploaded logo, as it does in /editpartner.
+func keepFieldsMissingFromCSV(p *Partner, prev Partner) {
+	if p.LogoURL == prev.LogoURL {
+		p.LogoFile = prev.LogoFile
+	}
+	p.PendingAnnouncement = prev.PendingAnnouncement
+	p.Admin = prev.Admin
+}
+
+// Compare two partner entries by their saved representation
+func partnersEqual(a, b Partner) bool {
+	ja, _ := json.Marshal(a)
+	jb, _ := json.Marshal(b)
+	return bytes.Equal(ja, jb)
+}
+
+// Names of partners added, changed and removed going from old to next
+func diffPartners(old, next []Partner) (added, changed, removed []string) {
+	oldByName := map[string]Partner{}
+	for _, p := range old {
+		oldByName[strings.ToLower(strings.TrimSpace(p.Name))] = p
+	}
+	newNames := map[string]bool{}
+	for _, p := range next {
+		key := strings.ToLower(strings.TrimSpace(p.Name))
+		newNames[key] = true
+		prev, ok := oldByName[key]
+		switch {
+		case !ok:
+			added = append(added, p.Name)
+		case !partnersEqual(prev, p):
+			changed = append(changed, p.Name)
+		}
+	}
+	for _, p := range old {
+		if !newNames[strings.ToLower(strings.TrimSpace(p.Name))] {
+			removed = append(removed, p.Name)
+		}
+	}
+	return added, changed, removed
+}
+
+// Render a list of names for an embed field, keeping within the field limit
+func formatNameList(names []string) string {
+	if len(names) == 0 {
+		return "None"
+	}
+	out := ""
+	for idx, name := range names {
+		line := "• " + name + "\n"
+		if len(out)+len(line) > maxEmbedFieldValueLength-20 {
+			return out + fmt.Sprintf("…and %d more", len(names)-idx)
+		}
+		out += line
+	}
+	return out
+}
+
+// Handle /exportpartners: attach the partner list as JSON or CSV
+func handleExportPartnersCommand(s *discordgo.Session, i *discordgo.InteractionCreate) {
+	if !hasRole(i.Member.Roles, addPartnerRoleID) {
+		respondEphemeral(s, i, "You do not have permission to use this command.")
+		return
+	}
+	opts := optionMap(i.ApplicationCommandData().Options)
+	format := "json"
+	if opt, ok := opts["format"]; ok {
+		format = opt.StringValue()
+	}
+	var (
+		b           []byte
+		err         error
+		contentType string
+	)
+	partnersMu.RLock()
+	list := currentPartners()
+	partnersMu.RUnlock()
+	if format == "csv" {
+		b, err = partnersToCSV(list)
+		contentType = "text/csv"
+	} else {
+		b, err = json.MarshalIndent(list, "", "  ")
+		contentType = "application/json"
+	}
+	if err != nil {
+		respondEphemeral(s, i, "Failed to export partners: "+err.Error())
+		return
+	}
+	s.InteractionRespond(i.Interaction, &discordgo.InteractionResponse{
+		Type: discordgo.InteractionResponseChannelMessageWithSource,
+		Data: &discordgo.InteractionResponseData{
+			Content: fmt.Sprintf("Exported %d partner(s).", len(list)),
+			Files:   []*discordgo.File{{Name: "partners." + format, ContentType: contentType, Reader: bytes.NewReader(b)}},
+			Flags:   discordgo.MessageFlagsEphemeral,
+		},
+	})
+}
+
+// Handle /importpartners: validate an attachment and show a diff preview to confirm
+func handleImportPartnersCommand(s *discordgo.Session, i *discordgo.InteractionCreate) {
+	if !hasRole(i.Member.Roles, addPartnerRoleID) {
+		respondEphemeral(s, i, "You do not have permission to use this command.")
+		return
+	}
+	data := i.ApplicationCommandData()
+	opts := optionMap(data.Options)
+	att := data.Resolved.Attachments[opts["file"].Value.(string)]
+	if att == nil {
+		respondEphemeral(s, i, "Could not read the attached file.")
+		return
+	}
+
+	// Downloading the file and checking every partner's emoji can outlast the interaction deadline
+	s.InteractionRespond(i.Interaction, &discordgo.InteractionResponse{
+		Type: discordgo.InteractionResponseDeferredChannelMessageWithSource,
+		Data: &discordgo.InteractionResponseData{Flags: discordgo.MessageFlagsEphemeral},
+	})
+	reply := func(content string) {
+		s.InteractionResponseEdit(i.Interaction, &discordgo.WebhookEdit{Content: &content})
+	}
+	raw, err := downloadAttachment(att, maxPartnerImportSize)
+	if err != nil {
+		reply("Could not download the attached file: " + err.Error())
+		return
+	}
+	var imported []Partner
+	isCSV := strings.HasSuffix(strings.ToLower(att.Filename), ".csv")
+	if isCSV {
+		imported, err = partnersFromCSV(raw)
+	} else {
+		err = json.Unmarshal(raw, &imported)
+	}
+	if err != nil {
+		reply("Could not parse the attached file: " + err.Error())
+		return
+	}
+
+	// Reject duplicate names and validate anything that differs from what's saved
+	// now. Validation makes Discord calls, so it runs against a copy of the list;
+	// applying the import diffs against the live list again.
+	partnersMu.RLock()
+	before := currentPartners()
+	partnersMu.RUnlock()
+	seen := map[string]bool{}
+	current := map[string]Partner{}
+	for _, p := range before {
+		current[strings.ToLower(strings.TrimSpace(p.Name))] = p
+	}
+	var problems []string
+	for idx := range imported {
+		p := &imported[idx]
+		p.Name = strings.TrimSpace(p.Name)
+		key := strings.ToLower(p.Name)
+		if seen[key] {
+			problems = append(problems, fmt.Sprintf("**%s**: duplicate name", p.Name))
+			continue
+		}
+		seen[key] = true
+		prev, ok := current[key]
+		if ok && isCSV {
+			keepFieldsMissingFromCSV(p, prev)
+		}
+		// Logo files are only ever written by the bot, so an import may keep a
+		// partner's stored logo but never point it at another file
+		if p.LogoFile != "" && (!ok || p.LogoFile != prev.LogoFile) {
+			problems = append(problems, fmt.Sprintf("**%s** › logo_file can't be imported; upload the logo with /editpartner instead", p.Name))
+			continue
+		}
+		if ok && partnersEqual(prev, *p) {
+			continue
+		}
+		for _, e := range validatePartner(s, p) {
+			problems = append(problems, fmt.Sprintf("**%s** › %s", p.Name, e.Error()))
+		}
+	}
+	if len(problems) > 0 {
+		msg := "The import wasn't applied:\n• " + strings.Join(problems, "\n• ")
+		if len(msg) > 1900 {
+			msg = msg[:1900] + "\n…"
+		}
+		reply(msg)
+		return
+	}
+
+	added, changed, removed := diffPartners(before, imported)
+	if len(added)+len(changed)+len(removed) == 0 {
+		reply("The file matches the current partner list; nothing to import.")
+		return
+	}
+
+	idBytes := make([]byte, 8)
+	rand.Read(idBytes)
+	id := hex.EncodeToString(idBytes)
+	pendingPartnerImportsMu.Lock()
+	for key, pending := range pendingPartnerImports {
+		if time.Now().After(pending.Expires) {
+			delete(pendingPartnerImports, key)
+		}
+	}
+	pendingPartnerImports[id] = &pendingPartnerImport{
+		UserID:   i.Member.User.ID,
+		Partners: imported,
+		CSV:      isCSV,
+		Expires:  time.Now().Add(partnerImportTTL),
+	}
+	pendingPartnerImportsMu.Unlock()
+
+	embed := &discordgo.MessageEmbed{
+		Title:       "Import Preview",
+		Description: fmt.Sprintf("%s will replace the current list of %d partner(s) with %d partner(s).", att.Filename, len(before), len(imported)),
+		Fields: []*discordgo.MessageEmbedField{
+			{Name: fmt.Sprintf("Added (%d)", len(added)), Value: formatNameList(added)},
+			{Name: fmt.Sprintf("Changed (%d)", len(changed)), Value: formatNameList(changed)},
+			{Name: fmt.Sprintf("Removed (%d)", len(removed)), Value: formatNameList(removed)},
+		},
+	}
+	s.InteractionResponseEdit(i.Interaction, &discordgo.WebhookEdit{
+		Embeds: &[]*discordgo.MessageEmbed{embed},
+		Components: &[]discordgo.MessageComponent{
+			discordgo.ActionsRow{Components: []discordgo.MessageComponent{
+				discordgo.Button{Label: "Apply Import", CustomID: "partnerimport_confirm_" + id, Style: discordgo.SuccessButton},
+				discordgo.Button{Label: "Cancel", CustomID: "partnerimport_cancel_" + id, Style: discordgo.SecondaryButton},
+			}},
+		},
+	})
+}
+
+// Handle the Apply/Cancel buttons on an import preview
+func handlePartnerImportButton(s *discordgo.Session, i *discordgo.InteractionCreate) {
+	customID := i.MessageComponentData().CustomID
+	confirm := strings.HasPrefix(customID, "partnerimport_confirm_")
+	id := strings.TrimPrefix(strings.TrimPrefix(customID, "partnerimport_confirm_"), "partnerimport_cancel_")
+
+	pendingPartnerImportsMu.Lock()
+	pending, ok := pendingPartnerImports[id]
+	if ok && pending.UserID == i.Member.User.ID {
+		delete(pendingPartnerImports, id)
+	}
+	pendingPartnerImportsMu.Unlock()
+
+	update := func(content string) {
+		s.InteractionRespond(i.Interaction, &discordgo.InteractionResponse{
+			Type: discordgo.InteractionResponseUpdateMessage,
+			Data: &discordgo.InteractionResponseData{
+				Content:    content,
+				Embeds:     []*discordgo.MessageEmbed{},
+				Components: []discordgo.MessageComponent{},
+			},
+		})
+	}
+	switch {
+	case !ok || time.Now().After(pending.Expires):
+		update("This import preview has expired. Run /importpartners again.")
+		return
+	case pending.UserID != i.Member.User.ID:
+		respondEphemeral(s, i, "Only the admin who started this import can apply it.")
+		return
+	case !confirm:
+		update("Import cancelled.")
+		return
+	}
+
+	// Partners missing from the import are soft-deleted so they can still be restored
+	partnersMu.Lock()
+	before := currentPartners()
+	previous := partners
+	next := append([]Partner(nil), pending.Partners...)
+	if pending.CSV {
+		// Pick up anything set on the saved partners since the preview
+		for idx := range next {
+			if p := findPartner(next[idx].Name); p != nil {
+				keepFieldsMissingFromCSV(&next[idx], *p)
+			}
+		}
+	}
+	added, changed, removed := diffPartners(before, next)
+	now := time.Now().UTC()
+	imported := map[string]bool{}
+	for _, p := range next {
+		imported[strings.ToLower(strings.TrimSpace(p.Name))] = true
+	}
+	for _, p := range previous {
+		if imported[strings.ToLower(strings.TrimSpace(p.Name))] {
+			continue
+		}
+		if p.DeletedAt == nil {
+			p.DeletedAt = &now
+			p.DeletedBy = i.Member.User.ID
+		}
+		next = append(next, p)
+	}
+	partners = next
+	if err := savePartners(); err != nil {
+		partners = previous
+		partnersMu.Unlock()
+		log.Printf("Error saving imported partners: %v", err)
+		update("Failed to save the import; the partner list is unchanged.")
+		return
+	}
+	for _, name := range append(added, changed...) {
+		if p := findPartner(name); p != nil {
+			recordPartnerRevision(partnerActionImport, i.Member.User.ID, *p)
+		}
+	}
+	for _, name := range removed {
+		if p := findDeletedPartner(name); p != nil {
+			recordPartnerRevision(partnerActionDelete, i.Member.User.ID, *p)
+		}
+	}
+	partnersMu.Unlock()
+	update(fmt.Sprintf("Import applied. %d partner(s) saved and the panel is refreshing.", len(pending.Partners)))
+	refreshPartnersPanel(s)
+}
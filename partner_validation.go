@@ -0,0 +1,182 @@
+package main
+
+import (
+	"fmt"
+	"net/url"
+	"path"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+
+	"github.com/bwmarrin/discordgo"
+)
+
+// --- Partner Validation Section ---
+
+// Discord limits that partner fields end up rendered into
+const (
+	maxPartnerNameLength       = 80   // Button label limit
+	maxEmbedDescriptionLength  = 4096 // Partner description + offering share the embed description
+	maxEmbedFieldValueLength   = 1024
+	maxEmbedTotalLength        = 6000
+	partnerOfferingSeparator   = "\n\n**Offering:** "
+	partnerAccessFieldName     = "Access Offering"
+	maxPartnerEmojiRunesLength = 16
+)
+
+// A problem with one partner field
+type partnerFieldError struct {
+	Field   string
+	Problem string
+}
+
+func (e partnerFieldError) Error() string {
+	return e.Field + ": " + e.Problem
+}
+
+// Format field errors as a reply to the admin
+func formatPartnerErrors(errs []partnerFieldError) string {
+	var b strings.Builder
+	b.WriteString("The partner wasn't saved:")
+	for _, e := range errs {
+		fmt.Fprintf(&b, "\n• **%s**: %s", e.Field, e.Problem)
+	}
+	return b.String()
+}
+
+// Check a URL is absolute http(s)
+func validateHTTPURL(raw string) string {
+	u, err := url.Parse(strings.TrimSpace(raw))
+	if err != nil || raw == "" {
+		return "must be a valid URL"
+	}
+	if u.Scheme != "http" && u.Scheme != "https" {
+		return "must start with http:// or https://"
+	}
+	if u.Host == "" {
+		return "is missing a host name"
+	}
+	return ""
+}
+
+// Check an emoji string is a unicode emoji or a custom emoji from this guild
+func validatePartnerEmoji(s *discordgo.Session, input string) string {
+	input = strings.TrimSpace(input)
+	if input == "" {
+		return "is required"
+	}
+	name, id, _ := parseEmoji(input)
+	if id != "" {
+		emojis, err := s.GuildEmojis(config.GuildID)
+		if err != nil {
+			return "couldn't check the server's emojis, please try again"
+		}
+		for _, e := range emojis {
+			if e.ID == id {
+				return ""
+			}
+		}
+		return fmt.Sprintf("custom emoji :%s: isn't available in this server", name)
+	}
+	if utf8.RuneCountInString(input) > maxPartnerEmojiRunesLength {
+		return "must be a single emoji"
+	}
+	if !isUnicodeEmoji(input) {
+		return "must be a unicode emoji or a custom emoji like <:name:id>"
+	}
+	return ""
+}
+
+// Unicode blocks that emoji are drawn from
+var emojiRanges = &unicode.RangeTable{
+	R16: []unicode.Range16{
+		{Lo: 0x00a9, Hi: 0x00ae, Stride: 5}, // © ®
+		{Lo: 0x203c, Hi: 0x203c, Stride: 1},
+		{Lo: 0x2049, Hi: 0x2049, Stride: 1},
+		{Lo: 0x2122, Hi: 0x2139, Stride: 0x17}, // ™ ℹ
+		{Lo: 0x2194, Hi: 0x21aa, Stride: 1},
+		{Lo: 0x231a, Hi: 0x23ff, Stride: 1},
+		{Lo: 0x24c2, Hi: 0x24c2, Stride: 1},
+		{Lo: 0x25aa, Hi: 0x25fe, Stride: 1},
+		{Lo: 0x2600, Hi: 0x27bf, Stride: 1},
+		{Lo: 0x2934, Hi: 0x2935, Stride: 1},
+		{Lo: 0x2b05, Hi: 0x2b55, Stride: 1},
+		{Lo: 0x3030, Hi: 0x3030, Stride: 1},
+		{Lo: 0x303d, Hi: 0x303d, Stride: 1},
+		{Lo: 0x3297, Hi: 0x3299, Stride: 2},
+	},
+	R32: []unicode.Range32{
+		{Lo: 0x1f000, Hi: 0x1faff, Stride: 1},
+	},
+	LatinOffset: 1,
+}
+
+// Report whether s is made only of emoji and the joiners, variation selectors,
+// skin tones and tags that combine them. Digits, # and * count only as keycaps.
+func isUnicodeEmoji(s string) bool {
+	hasEmoji, keycap := false, strings.ContainsRune(s, '\u20e3')
+	for _, r := range s {
+		switch {
+		case unicode.Is(emojiRanges, r):
+			hasEmoji = true
+		case r == '\u200d', r == '\ufe0e', r == '\ufe0f', r == '\u20e3', r >= 0xe0020 && r <= 0xe007f:
+		case keycap && (r == '#' || r == '*' || r >= '0' && r <= '9'):
+			hasEmoji = true
+		default:
+			return false
+		}
+	}
+	return hasEmoji
+}
+
+// Validate a partner against Discord embed and component limits before it is saved
+func validatePartner(s *discordgo.Session, p *Partner) []partnerFieldError {
+	var errs []partnerFieldError
+	add := func(field, problem string) {
+		if problem != "" {
+			errs = append(errs, partnerFieldError{field, problem})
+		}
+	}
+
+	nameLen := utf8.RuneCountInString(strings.TrimSpace(p.Name))
+	if nameLen == 0 {
+		add("name", "is required")
+	} else if nameLen > maxPartnerNameLength {
+		add("name", fmt.Sprintf("is %d characters; the limit is %d", nameLen, maxPartnerNameLength))
+	}
+
+	descLen := utf8.RuneCountInString(p.Description)
+	offerLen := utf8.RuneCountInString(p.Offering)
+	if descLen == 0 {
+		add("description", "is required")
+	}
+	if offerLen == 0 {
+		add("offering", "is required")
+	}
+	if total := descLen + offerLen + utf8.RuneCountInString(partnerOfferingSeparator); total > maxEmbedDescriptionLength {
+		add("description", fmt.Sprintf("together with offering is %d characters; the limit is %d", total, maxEmbedDescriptionLength))
+	}
+
+	// An uploaded logo file stands in for the logo URL
+	if p.LogoFile == "" || p.LogoURL != "" {
+		add("logo", validateHTTPURL(p.LogoURL))
+		if u, err := url.Parse(p.LogoURL); err == nil && strings.EqualFold(path.Ext(u.Path), ".svg") {
+			add("logo", "SVG images don't render in Discord embeds; use a PNG, JPEG or GIF, or upload a logo_file")
+		}
+	}
+	add("link", validateHTTPURL(p.Link))
+	add("emoji", validatePartnerEmoji(s, p.Emoji))
+
+	errs = append(errs, validatePartnerMedia(s, p)...)
+
+	notEligibleLen := utf8.RuneCountInString(p.notEligibleMessage())
+	if notEligibleLen > maxEmbedFieldValueLength {
+		add("message", fmt.Sprintf("is %d characters; the limit is %d", notEligibleLen, maxEmbedFieldValueLength))
+	}
+	total := nameLen + descLen + offerLen + utf8.RuneCountInString(partnerOfferingSeparator) +
+		utf8.RuneCountInString(partnerAccessFieldName) + notEligibleLen + utf8.RuneCountInString(p.Footer)
+	if total > maxEmbedTotalLength {
+		add("description", fmt.Sprintf("the partner embed would be %d characters; Discord's limit is %d", total, maxEmbedTotalLength))
+	}
+	return errs
+}
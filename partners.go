@@ -2,31 +2,113 @@ package main
 
 import (
 	"encoding/json"
+	"fmt"
 	"os"
 	"log"
+	"strings"
+	"sync"
+	"time"
+
 	"github.com/bwmarrin/discordgo"
 )
 
 // --- Partner Feature Section ---
 
 type Partner struct {
-	Name        string `json:"name"`
-	Description string `json:"description"`
-	Offering    string `json:"offering"`
-	LogoURL     string `json:"logo_url"`
-	Link        string `json:"link"`
-	Emoji       string `json:"emoji"`
+	Name        string   `json:"name"`
+	Description string   `json:"description"`
+	Offering    string   `json:"offering"`
+	LogoURL     string   `json:"logo_url"`
+	LogoFile    string   `json:"logo_file,omitempty"` // Uploaded logo in partner_assets, preferred over LogoURL
+	Link        string   `json:"link"`
+	Emoji       string   `json:"emoji"`
+	Tags        []string `json:"tags,omitempty"`
+
+	// Optional offer card extras
+	Links     []PartnerLink `json:"links,omitempty"`
+	BannerURL string        `json:"banner_url,omitempty"`
+	Color     string        `json:"color,omitempty"`
+	Footer    string        `json:"footer,omitempty"`
+
+	Eligibility *PartnerEligibility `json:"eligibility,omitempty"`
+
+	// Optional deal window; the partner only appears on the panel between these times
+	StartsAt *time.Time `json:"starts_at,omitempty"`
+	EndsAt   *time.Time `json:"ends_at,omitempty"`
+
+	PendingAnnouncement *PartnerAnnouncement `json:"pending_announcement,omitempty"`
+
+	// Private admin-only details
+	Admin *PartnerAdminInfo `json:"admin,omitempty"`
+
+	// Soft delete markers; deleted partners stay in the file so they can be restored
+	DeletedAt *time.Time `json:"deleted_at,omitempty"`
+	DeletedBy string     `json:"deleted_by,omitempty"`
 }
 
 var partners []Partner
 const partnersFile = "partners.json"
 
+// Guards partners. Interaction handlers, the partner scheduler and the redirect
+// server all run on their own goroutines; the helpers below expect the caller
+// to hold it.
+var partnersMu sync.RWMutex
+
 const (
 	partnersChannelID   = "1365774652986626109"
 	accessRoleID        = "1311141977558876201"
 	addPartnerRoleID    = "1311142224716890145"
 )
 
+// Report whether the partner's deal window includes now
+func (p *Partner) isLive(now time.Time) bool {
+	if p.DeletedAt != nil {
+		return false
+	}
+	if p.StartsAt != nil && now.Before(*p.StartsAt) {
+		return false
+	}
+	if p.EndsAt != nil && !now.Before(*p.EndsAt) {
+		return false
+	}
+	return true
+}
+
+// Find a partner that hasn't been deleted by name, ignoring case and surrounding whitespace.
+// Callers must hold partnersMu.
+func findPartner(name string) *Partner {
+	name = strings.TrimSpace(name)
+	for idx := range partners {
+		if partners[idx].DeletedAt == nil && strings.EqualFold(strings.TrimSpace(partners[idx].Name), name) {
+			return &partners[idx]
+		}
+	}
+	return nil
+}
+
+// Partners that haven't been deleted, whether or not they are currently scheduled
+func currentPartners() []Partner {
+	var list []Partner
+	for _, p := range partners {
+		if p.DeletedAt == nil {
+			list = append(list, p)
+		}
+	}
+	return list
+}
+
+// Partners currently shown on the panel. Callers must hold partnersMu.
+func livePartners() []Partner {
+	now := time.Now()
+	var live []Partner
+	for _, p := range partners {
+		if p.isLive(now) {
+			live = append(live, p)
+		}
+	}
+	return live
+}
+
 // Load partners from file
 func loadPartners() error {
 	b, err := os.ReadFile(partnersFile)
@@ -40,13 +122,13 @@ func loadPartners() error {
 	return json.Unmarshal(b, &partners)
 }
 
-// Save partners to file
+// Save partners to file. Callers must hold partnersMu.
 func savePartners() error {
 	b, err := json.MarshalIndent(partners, "", "  ")
 	if err != nil {
 		return err
 	}
-	return os.WriteFile(partnersFile, b, 0644)
+	return writeFileAtomic(partnersFile, b, 0644)
 }
 
 // Check for existing bot embed in the channel
@@ -63,7 +145,25 @@ func hasBotEmbedInChannel(s *discordgo.Session, channelID, botUserID string) (bo
 	return false, nil
 }
 
-// Send the partners embed with buttons
+// Buttons for the partners currently shown on the panel. Takes partnersMu
+// itself, so the panel can be sent without holding it across Discord calls.
+func partnerPanelButtons() []discordgo.MessageComponent {
+	partnersMu.RLock()
+	defer partnersMu.RUnlock()
+	var buttons []discordgo.MessageComponent
+	for _, p := range livePartners() {
+		name, id, animated := parseEmoji(p.Emoji)
+		buttons = append(buttons, discordgo.Button{
+			Label:    p.Name,
+			Emoji:    &discordgo.ComponentEmoji{Name: name, ID: id, Animated: animated},
+			CustomID: "partner_" + p.Name,
+			Style:    discordgo.PrimaryButton,
+		})
+	}
+	return buttons
+}
+
+// Send the partners embed with buttons. Callers must not hold partnersMu.
 func sendPartnersEmbed(s *discordgo.Session) {
 	botUser, err := s.User("@me")
 	var avatarURL string
@@ -79,16 +179,7 @@ func sendPartnersEmbed(s *discordgo.Session) {
 		embed.Thumbnail = &discordgo.MessageEmbedThumbnail{URL: avatarURL}
 	}
 
-	var buttons []discordgo.MessageComponent
-	for _, p := range partners {
-		name, id, animated := parseEmoji(p.Emoji)
-		buttons = append(buttons, discordgo.Button{
-			Label:    p.Name,
-			Emoji:    &discordgo.ComponentEmoji{Name: name, ID: id, Animated: animated},
-			CustomID: "partner_" + p.Name,
-			Style:    discordgo.PrimaryButton,
-		})
-	}
+	buttons := partnerPanelButtons()
 
 	if len(buttons) == 0 {
 		s.ChannelMessageSend(partnersChannelID, "No partners configured yet.")
@@ -104,7 +195,7 @@ func sendPartnersEmbed(s *discordgo.Session) {
 	}
 }
 
-// Update the partners embed/buttons in-place
+// Update the partners embed/buttons in-place. Callers must not hold partnersMu.
 func updatePartnersEmbed(s *discordgo.Session, botUserID string) {
 	messages, err := s.ChannelMessages(partnersChannelID, 50, "", "", "")
 	if err != nil {
@@ -135,22 +226,15 @@ func updatePartnersEmbed(s *discordgo.Session, botUserID string) {
 	if avatarURL != "" {
 		embed.Thumbnail = &discordgo.MessageEmbedThumbnail{URL: avatarURL}
 	}
-	var buttons []discordgo.MessageComponent
-	for _, p := range partners {
-		name, id, animated := parseEmoji(p.Emoji)
-		buttons = append(buttons, discordgo.Button{
-			Label:    p.Name,
-			Emoji:    &discordgo.ComponentEmoji{Name: name, ID: id, Animated: animated},
-			CustomID: "partner_" + p.Name,
-			Style:    discordgo.PrimaryButton,
-		})
-	}
+	buttons := partnerPanelButtons()
 
 	edit := &discordgo.MessageEdit{
 		ID:      botMsg.ID,
 		Channel: partnersChannelID,
 		Embeds:  &[]*discordgo.MessageEmbed{embed},
 	}
+	// Always set components so partners that have gone off the panel lose their buttons
+	edit.Components = &[]discordgo.MessageComponent{}
 	if len(buttons) > 0 {
 		edit.Components = &[]discordgo.MessageComponent{
 			discordgo.ActionsRow{Components: buttons},
@@ -163,5 +247,94 @@ func updatePartnersEmbed(s *discordgo.Session, botUserID string) {
 	}
 }
 
+// Resolve the user and guild roles behind an interaction. Interactions from DMs
+// carry no member, so roles are fetched from the guild; non-members get no roles.
+func interactionMember(s *discordgo.Session, i *discordgo.InteractionCreate) (userID string, roles []string) {
+	if i.Member != nil {
+		return i.Member.User.ID, i.Member.Roles
+	}
+	if i.User == nil {
+		return "", nil
+	}
+	member, err := s.GuildMember(config.GuildID, i.User.ID)
+	if err != nil {
+		return i.User.ID, nil
+	}
+	return i.User.ID, member.Roles
+}
+
+// Reply with a partner's ephemeral offering view, showing the link only to eligible members
+func respondWithPartnerView(s *discordgo.Session, i *discordgo.InteractionCreate, p *Partner) {
+	if !p.isLive(time.Now()) {
+		respondEphemeral(s, i, "This partner offering isn't currently available.")
+		return
+	}
+	userID, roles := interactionMember(s, i)
+	thumbnail, logoFile := partnerThumbnail(p)
+	embed := &discordgo.MessageEmbed{
+		Title:       p.Name,
+		Description: fmt.Sprintf("%s\n\n**Offering:** %s", p.Description, p.Offering),
+		Thumbnail:   thumbnail,
+	}
+	decoratePartnerEmbed(embed, p)
+	recordPartnerEvent(p.Name, partnerEventView, userID, roles)
+	// Eligibility is evaluated against the member's roles at the time of the press
+	var components []discordgo.MessageComponent
+	if p.isEligible(roles) {
+		// With redirects the buttons only lead through the tracked redirect, which
+		// records the click; without them the partner's URLs themselves are revealed
+		if !redirectEnabled() {
+			recordPartnerEvent(p.Name, partnerEventLink, userID, roles)
+		}
+		components = append(components, partnerLinkButtons(p, userID, roles))
+		if hasCodePool(p.Name) {
+			components = append(components, discordgo.ActionsRow{Components: []discordgo.MessageComponent{
+				discordgo.Button{
+					Label:    "Redeem",
+					CustomID: "partner_redeem_" + p.Name,
+					Style:    discordgo.SuccessButton,
+					Emoji:    &discordgo.ComponentEmoji{Name: "🎟️"},
+				},
+			}})
+		}
+	} else {
+		embed.Fields = []*discordgo.MessageEmbedField{
+			{Name: "Access Offering", Value: p.notEligibleMessage(), Inline: false},
+		}
+	}
+	data := &discordgo.InteractionResponseData{
+		Embeds:     []*discordgo.MessageEmbed{embed},
+		Flags:      discordgo.MessageFlagsEphemeral,
+		Components: components,
+	}
+	if logoFile != nil {
+		data.Files = []*discordgo.File{logoFile}
+	}
+	s.InteractionRespond(i.Interaction, &discordgo.InteractionResponse{
+		Type: discordgo.InteractionResponseChannelMessageWithSource,
+		Data: data,
+	})
+}
+
+// Refresh the partners panel, clearing the "No partners configured yet." placeholder first.
+// Callers must not hold partnersMu.
+func refreshPartnersPanel(s *discordgo.Session) {
+	botUser, err := s.User("@me")
+	if err != nil {
+		log.Printf("Could not get bot user: %v", err)
+		return
+	}
+	messages, err := s.ChannelMessages(partnersChannelID, 50, "", "", "")
+	if err == nil {
+		for _, msg := range messages {
+			if msg.Author != nil && msg.Author.ID == botUser.ID && msg.Content == "No partners configured yet." {
+				_ = s.ChannelMessageDelete(partnersChannelID, msg.ID)
+				break
+			}
+		}
+	}
+	updatePartnersEmbed(s, botUser.ID)
+}
+
 // Partner slash command and button logic should be handled in handlers.go or onInteractionCreate,
 // but you can also put helper functions here if needed.
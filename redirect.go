@@ -0,0 +1,222 @@
+package main
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"log"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/bwmarrin/discordgo"
+)
+
+// --- Partner Link Redirect Section ---
+
+const defaultRedirectLinkTTL = 24 * time.Hour
+
+// Report whether the click-tracking redirect service is configured
+func redirectEnabled() bool {
+	return config.RedirectListenAddr != "" && config.RedirectBaseURL != "" && config.RedirectSecret != ""
+}
+
+// How long a signed partner link stays valid
+func redirectLinkTTL() time.Duration {
+	if config.RedirectLinkTTLHours > 0 {
+		return time.Duration(config.RedirectLinkTTLHours) * time.Hour
+	}
+	return defaultRedirectLinkTTL
+}
+
+func signRedirectPayload(payload string) string {
+	mac := hmac.New(sha256.New, []byte(config.RedirectSecret))
+	mac.Write([]byte(payload))
+	return base64.RawURLEncoding.EncodeToString(mac.Sum(nil)[:16])
+}
+
+// Contents of a signed partner link. The fields are JSON encoded so nothing in a
+// partner name can be mistaken for a field boundary.
+type redirectPayload struct {
+	Partner  string `json:"p"`
+	UserHash string `json:"u"`
+	Tier     string `json:"t"`
+	Expires  int64  `json:"e"`
+	LinkID   string `json:"l"`
+
+	legacyIndex int // Position of the link in links issued before LinkID existed
+}
+
+// Stable identifier for one of a partner's links. Links are matched by their URL
+// rather than their position, so adding or reordering links can't send an issued
+// link somewhere else.
+func partnerLinkID(l PartnerLink) string {
+	sum := sha256.Sum256([]byte(l.URL))
+	return base64.RawURLEncoding.EncodeToString(sum[:8])
+}
+
+// Build a per-member signed short link for one of a partner's links.
+// The token carries the hashed user and tier so clicks can be attributed without storing IDs.
+func signedPartnerLink(partnerName string, link PartnerLink, userID string, roles []string) string {
+	payload, _ := json.Marshal(redirectPayload{
+		Partner:  partnerName,
+		UserHash: hashUserID(userID),
+		Tier:     roleTier(roles),
+		Expires:  time.Now().Add(redirectLinkTTL()).Unix(),
+		LinkID:   partnerLinkID(link),
+	})
+	encoded := base64.RawURLEncoding.EncodeToString(payload)
+	return strings.TrimRight(config.RedirectBaseURL, "/") + "/r/" + encoded + "." + signRedirectPayload(encoded)
+}
+
+// Link for a member: signed redirect when enabled, otherwise the partner's own URL
+func partnerLinkFor(p *Partner, link PartnerLink, userID string, roles []string) string {
+	if redirectEnabled() {
+		return signedPartnerLink(p.Name, link, userID, roles)
+	}
+	return link.URL
+}
+
+// Decode a verified link payload. Links issued before payloads were JSON joined
+// their fields with "|" and pointed at a link by position; they are still
+// accepted until they expire.
+func decodeRedirectPayload(raw []byte) (redirectPayload, error) {
+	var payload redirectPayload
+	if strings.HasPrefix(string(raw), "{") {
+		err := json.Unmarshal(raw, &payload)
+		if err == nil && payload.LinkID == "" {
+			err = fmt.Errorf("missing link")
+		}
+		return payload, err
+	}
+	parts := strings.Split(string(raw), "|")
+	if len(parts) == 4 {
+		parts = append(parts, "0")
+	}
+	if len(parts) != 5 {
+		return payload, fmt.Errorf("expected 5 fields, got %d", len(parts))
+	}
+	linkIndex, err := strconv.Atoi(parts[4])
+	if err != nil {
+		return payload, err
+	}
+	expires, err := strconv.ParseInt(parts[3], 10, 64)
+	if err != nil {
+		return payload, err
+	}
+	return redirectPayload{Partner: parts[0], UserHash: parts[1], Tier: parts[2], Expires: expires, legacyIndex: linkIndex}, nil
+}
+
+// Find the link a payload points at among a partner's current links
+func (payload redirectPayload) resolve(p *Partner) (PartnerLink, bool) {
+	links := p.allLinks()
+	if payload.LinkID == "" {
+		if payload.legacyIndex >= 0 && payload.legacyIndex < len(links) {
+			return links[payload.legacyIndex], true
+		}
+		return PartnerLink{}, false
+	}
+	for _, l := range links {
+		if partnerLinkID(l) == payload.LinkID {
+			return l, true
+		}
+	}
+	return PartnerLink{}, false
+}
+
+// Handle GET /r/<payload>.<signature>: verify, record the click, then redirect
+func handlePartnerRedirect(w http.ResponseWriter, r *http.Request) {
+	token := strings.TrimPrefix(r.URL.Path, "/r/")
+	encoded, sig, ok := strings.Cut(token, ".")
+	if !ok || !hmac.Equal([]byte(sig), []byte(signRedirectPayload(encoded))) {
+		http.Error(w, "Invalid link.", http.StatusNotFound)
+		return
+	}
+	raw, err := base64.RawURLEncoding.DecodeString(encoded)
+	if err != nil {
+		http.Error(w, "Invalid link.", http.StatusNotFound)
+		return
+	}
+	payload, err := decodeRedirectPayload(raw)
+	if err != nil {
+		http.Error(w, "Invalid link.", http.StatusNotFound)
+		return
+	}
+	if time.Now().Unix() > payload.Expires {
+		http.Error(w, "This link has expired. Press the partner button in Discord again for a fresh one.", http.StatusGone)
+		return
+	}
+
+	var link PartnerLink
+	found := false
+	partnersMu.RLock()
+	for _, p := range partners {
+		if p.Name == payload.Partner && p.isLive(time.Now()) {
+			link, found = payload.resolve(&p)
+			break
+		}
+	}
+	partnersMu.RUnlock()
+	if !found || link.URL == "" {
+		http.Error(w, "This partner is no longer available.", http.StatusNotFound)
+		return
+	}
+	appendPartnerEvent(PartnerEvent{
+		Time:     time.Now().UTC(),
+		Partner:  payload.Partner,
+		Kind:     partnerEventClick,
+		UserHash: payload.UserHash,
+		Tier:     payload.Tier,
+		Link:     link.Label,
+	})
+	http.Redirect(w, r, link.URL, http.StatusFound)
+}
+
+// Address the bot's HTTP server listens on, or "" when it is disabled
+func httpListenAddr() string {
+	if config.HTTPListenAddr != "" {
+		return config.HTTPListenAddr
+	}
+	return config.RedirectListenAddr
+}
+
+// Start the bot's HTTP server for any enabled web features
+func startHTTPServer(s *discordgo.Session) {
+	addr := httpListenAddr()
+	if addr == "" {
+		return
+	}
+	mux := http.NewServeMux()
+	if redirectEnabled() {
+		mux.HandleFunc("/r/", handlePartnerRedirect)
+	}
+	mux.Handle(webhookPathPrefix, notificationWebhookHandler(s))
+	srv := &http.Server{
+		Addr:              addr,
+		Handler:           mux,
+		ReadHeaderTimeout: 10 * time.Second,
+	}
+	go func() {
+		log.Printf("HTTP server listening on %s", addr)
+		if err := srv.ListenAndServe(); err != nil && err != http.ErrServerClosed {
+			log.Printf("HTTP server error: %v", err)
+		}
+	}()
+}
+
+// Validate redirect settings so a half-configured server is reported at startup
+func checkRedirectConfig() error {
+	set := 0
+	for _, v := range []string{config.RedirectListenAddr, config.RedirectBaseURL, config.RedirectSecret} {
+		if v != "" {
+			set++
+		}
+	}
+	if set != 0 && set != 3 {
+		return fmt.Errorf("redirectListenAddr, redirectBaseUrl and redirectSecret must all be set to enable partner link tracking")
+	}
+	return nil
+}
@@ -0,0 +1,205 @@
+package main
+
+import (
+	"fmt"
+	"log"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/bwmarrin/discordgo"
+)
+
+// --- Role Preflight Section ---
+
+// How often the same role assignment failure is explained in the admin channel
+const roleFailureAlertInterval = time.Hour
+
+// A role to check, and whether the bot needs to be able to assign it
+type roleCheck struct {
+	Label  string
+	RoleID string
+	Assign bool
+}
+
+// The guild's roles and what the bot is allowed to do with them
+type roleContext struct {
+	roles     map[string]*discordgo.Role
+	botTop    int
+	canManage bool
+}
+
+var (
+	roleFailureAlerts   = map[string]time.Time{}
+	roleFailureAlertsMu sync.Mutex
+)
+
+// Fetch the guild roles and the bot's highest role and permissions
+func loadRoleContext(s *discordgo.Session) (*roleContext, error) {
+	roles, err := s.GuildRoles(config.GuildID)
+	if err != nil {
+		return nil, fmt.Errorf("fetching guild roles: %w", err)
+	}
+	bot, err := s.GuildMember(config.GuildID, s.State.User.ID)
+	if err != nil {
+		return nil, fmt.Errorf("fetching the bot's member: %w", err)
+	}
+	rc := &roleContext{roles: map[string]*discordgo.Role{}}
+	for _, r := range roles {
+		rc.roles[r.ID] = r
+	}
+	// @everyone shares the guild's ID and applies to the bot too
+	for _, id := range append([]string{config.GuildID}, bot.Roles...) {
+		r := rc.roles[id]
+		if r == nil {
+			continue
+		}
+		if r.Position > rc.botTop {
+			rc.botTop = r.Position
+		}
+		if r.Permissions&(discordgo.PermissionManageRoles|discordgo.PermissionAdministrator) != 0 {
+			rc.canManage = true
+		}
+	}
+	return rc, nil
+}
+
+// Describe a role without mentioning it, so admin alerts don't ping anyone
+func (rc *roleContext) describe(roleID string) string {
+	if r := rc.roles[roleID]; r != nil {
+		return fmt.Sprintf("%q (%s)", r.Name, roleID)
+	}
+	return roleID
+}
+
+// Check a role exists, returning a problem description or ""
+func (rc *roleContext) exists(roleID string) string {
+	if rc.roles[roleID] == nil {
+		return fmt.Sprintf("role %s doesn't exist in this server", roleID)
+	}
+	return ""
+}
+
+// Check the bot can assign a role, returning a problem description or ""
+func (rc *roleContext) assignable(roleID string) string {
+	if problem := rc.exists(roleID); problem != "" {
+		return problem
+	}
+	r := rc.roles[roleID]
+	switch {
+	case roleID == config.GuildID:
+		return "@everyone can't be assigned"
+	case r.Managed:
+		return fmt.Sprintf("role %s is managed by an integration and can't be assigned", rc.describe(roleID))
+	case !rc.canManage:
+		return "the bot is missing the Manage Roles permission"
+	case r.Position >= rc.botTop:
+		return fmt.Sprintf("role %s sits at or above the bot's highest role; drag the bot's role above it in Server Settings → Roles", rc.describe(roleID))
+	}
+	return ""
+}
+
+// Run a list of role checks, returning one line per problem
+func (rc *roleContext) check(checks []roleCheck) []string {
+	var problems []string
+	for _, c := range checks {
+		if c.RoleID == "" {
+			continue
+		}
+		problem := rc.exists(c.RoleID)
+		if c.Assign {
+			problem = rc.assignable(c.RoleID)
+		}
+		if problem != "" {
+			problems = append(problems, fmt.Sprintf("**%s**: %s", c.Label, problem))
+		}
+	}
+	return problems
+}
+
+// Load the role context and run checks, reporting a fetch failure as a problem
+func preflightRoles(s *discordgo.Session, checks []roleCheck) []string {
+	rc, err := loadRoleContext(s)
+	if err != nil {
+		log.Printf("Role preflight failed: %v", err)
+		return []string{"couldn't check roles: " + err.Error()}
+	}
+	return rc.check(checks)
+}
+
+// Every role the bot is configured with
+func configuredRoleChecks() []roleCheck {
+	checks := []roleCheck{
+		{"roleFoundId", config.RoleFoundID, true},
+		{"roleNotFoundId", config.RoleNotFoundID, true},
+		{"partner access role", accessRoleID, false},
+		{"partner admin role", addPartnerRoleID, false},
+		{"partnerAnnouncementRoleId", config.PartnerAnnouncementRoleID, false},
+		{"proposePartnerRoleId", config.ProposePartnerRoleID, false},
+	}
+	for _, nc := range notificationChannelsSnapshot() {
+		checks = append(checks,
+			roleCheck{nc.Name + " access role", nc.AccessRoleID, true},
+			roleCheck{nc.Name + " notification role", nc.NotificationRoleID, true},
+		)
+	}
+	partnersMu.RLock()
+	for _, p := range currentPartners() {
+		checks = append(checks, eligibilityRoleChecks(p.Name, p.Eligibility)...)
+	}
+	partnersMu.RUnlock()
+	return checks
+}
+
+// Checks for the roles a partner's eligibility rule refers to
+func eligibilityRoleChecks(partnerName string, e *PartnerEligibility) []roleCheck {
+	if e == nil {
+		return nil
+	}
+	var checks []roleCheck
+	for _, group := range []struct {
+		name string
+		ids  []string
+	}{{"any_of", e.AnyOf}, {"all_of", e.AllOf}, {"none_of", e.NoneOf}} {
+		for _, id := range group.ids {
+			checks = append(checks, roleCheck{partnerName + " " + group.name, id, false})
+		}
+	}
+	return checks
+}
+
+// Check every configured role at startup and tell admins about any problems
+func checkConfiguredRoles(s *discordgo.Session) {
+	problems := preflightRoles(s, configuredRoleChecks())
+	if len(problems) == 0 {
+		return
+	}
+	for _, p := range problems {
+		log.Printf("Role preflight: %s", p)
+	}
+	sendAdminAlert(s, truncateRunes("⚠️ Some configured roles need attention:\n- "+strings.Join(problems, "\n- "), 2000))
+}
+
+// Explain a failed role assignment to admins, at most once an hour per role.
+// It makes REST calls, so interaction handlers run it in a goroutine.
+func explainRoleFailure(s *discordgo.Session, context string, roleIDs ...string) {
+	now := time.Now()
+	var checks []roleCheck
+	roleFailureAlertsMu.Lock()
+	for _, id := range roleIDs {
+		if now.Sub(roleFailureAlerts[id]) < roleFailureAlertInterval {
+			continue
+		}
+		roleFailureAlerts[id] = now
+		checks = append(checks, roleCheck{context, id, true})
+	}
+	roleFailureAlertsMu.Unlock()
+	if len(checks) == 0 {
+		return
+	}
+	problems := preflightRoles(s, checks)
+	if len(problems) == 0 {
+		problems = []string{"the roles look assignable, so this may have been a temporary Discord error"}
+	}
+	sendAdminAlert(s, "⚠️ A member couldn't be given a role:\n- "+strings.Join(problems, "\n- "))
+}
@@ -1,8 +1,14 @@
 package main
 
 import (
+	"errors"
+	"net/http"
+	"os"
+	"path/filepath"
 	"regexp"
 	"strings"
+
+	"github.com/bwmarrin/discordgo"
 )
 
 // Helper for *int values in struct literals (for DiscordGo components)
@@ -10,12 +16,123 @@ func intPtr(i int) *int {
 	return &i
 }
 
+// Helper for *float64 values in struct literals (for command option bounds)
+func floatPtr(f float64) *float64 {
+	return &f
+}
+
 // Helper: Parse a channel or role mention or ID into just the ID
 func parseID(input string) string {
 	// Handles <#channel>, <@&role>, <@role>, or raw IDs
 	return strings.Trim(input, "<#@&!>")
 }
 
+// Helper: Parse a list of role mentions or IDs separated by spaces or commas
+func parseIDList(input string) []string {
+	var ids []string
+	for _, field := range strings.FieldsFunc(input, func(r rune) bool { return r == ',' || r == ' ' }) {
+		if id := parseID(field); id != "" {
+			ids = append(ids, id)
+		}
+	}
+	return ids
+}
+
+// Helper: Parse comma separated tags, trimming and lowercasing each one
+func parseTags(input string) []string {
+	var tags []string
+	for _, t := range strings.Split(input, ",") {
+		if t = strings.ToLower(strings.TrimSpace(t)); t != "" {
+			tags = append(tags, t)
+		}
+	}
+	return tags
+}
+
+// Helper: Check whether a role ID is in a member's role list
+func hasRole(roles []string, roleID string) bool {
+	for _, r := range roles {
+		if r == roleID {
+			return true
+		}
+	}
+	return false
+}
+
+// Helper: Check whether the member running an interaction is a server administrator.
+// Backs up DefaultMemberPermissions, which server admins can override per command.
+func isAdministrator(i *discordgo.InteractionCreate) bool {
+	return i.Member != nil && i.Member.Permissions&discordgo.PermissionAdministrator != 0
+}
+
+// Helper: Index slash command options by name so optional options can be looked up safely
+func optionMap(opts []*discordgo.ApplicationCommandInteractionDataOption) map[string]*discordgo.ApplicationCommandInteractionDataOption {
+	m := make(map[string]*discordgo.ApplicationCommandInteractionDataOption, len(opts))
+	for _, opt := range opts {
+		m[opt.Name] = opt
+	}
+	return m
+}
+
+// Helper: Collect text input values from a modal submission, keyed by custom ID
+func modalValues(data discordgo.ModalSubmitInteractionData) map[string]string {
+	values := map[string]string{}
+	for _, c := range data.Components {
+		row, ok := c.(*discordgo.ActionsRow)
+		if !ok {
+			continue
+		}
+		for _, rc := range row.Components {
+			if input, ok := rc.(*discordgo.TextInput); ok {
+				values[input.CustomID] = input.Value
+			}
+		}
+	}
+	return values
+}
+
+// Helper: Send a plain ephemeral reply to an interaction
+func respondEphemeral(s *discordgo.Session, i *discordgo.InteractionCreate, content string) {
+	s.InteractionRespond(i.Interaction, &discordgo.InteractionResponse{
+		Type: discordgo.InteractionResponseChannelMessageWithSource,
+		Data: &discordgo.InteractionResponseData{
+			Content: content,
+			Flags:   discordgo.MessageFlagsEphemeral,
+		},
+	})
+}
+
+// Helper: Shorten a string to at most n characters, marking the cut with an ellipsis
+func truncateRunes(s string, n int) string {
+	r := []rune(s)
+	if len(r) <= n {
+		return s
+	}
+	return string(r[:n-1]) + "…"
+}
+
+// Helper: Write a file via a temp file and rename so readers never see a partial write
+func writeFileAtomic(filename string, data []byte, perm os.FileMode) error {
+	tmp, err := os.CreateTemp(filepath.Dir(filename), filepath.Base(filename)+".tmp-*")
+	if err != nil {
+		return err
+	}
+	if _, err := tmp.Write(data); err != nil {
+		tmp.Close()
+		os.Remove(tmp.Name())
+		return err
+	}
+	if err := tmp.Close(); err != nil {
+		os.Remove(tmp.Name())
+		return err
+	}
+	if err := os.Chmod(tmp.Name(), perm); err != nil {
+		os.Remove(tmp.Name())
+		return err
+	}
+	return os.Rename(tmp.Name(), filename)
+}
+
 // Emoji parsing helper
 var customEmojiPattern = regexp.MustCompile(`<a?:(\w+):(\d+)>`)
 
@@ -28,3 +145,14 @@ func parseEmoji(input string) (name, id string, animated bool) {
 	// fallback: handle Unicode emoji
 	return input, "", false
 }
+
+// Helper: Check whether a failed Discord request would fail the same way if retried,
+// e.g. missing permissions, a deleted channel or a member who doesn't accept DMs
+func isPermanentDiscordError(err error) bool {
+	var restErr *discordgo.RESTError
+	if !errors.As(err, &restErr) || restErr.Response == nil {
+		return false
+	}
+	code := restErr.Response.StatusCode
+	return code >= 400 && code < 500 && code != http.StatusTooManyRequests
+}